@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const alertRulesFilePath = "alert_rules.json" // File path for storing alert rules
+const alertLogFilePath = "alerts.log"         // Alert log, one tab-separated line per alert
+
+// ---------------------------------------------------------------------
+//  1) Alert Rules
+// ---------------------------------------------------------------------
+
+const (
+	alertDiskFreeBelow  = "Disk free below %"
+	alertMemoryAbove    = "Memory above %"
+	alertCPUAbove       = "CPU above %"
+	alertProcessMissing = "Process missing"
+)
+
+var alertKinds = []string{alertDiskFreeBelow, alertMemoryAbove, alertCPUAbove, alertProcessMissing}
+
+type AlertRule struct {
+	Name       string
+	Kind       string  // one of alertKinds
+	Threshold  float64 // percent, unused for "Process missing"
+	Target     string  // mount point or process name; empty disk target means every disk
+	ForSeconds int     // how long the condition must hold before alerting
+	Enabled    bool
+}
+
+type AlertEvent struct {
+	Timestamp string
+	Rule      string
+	Message   string
+}
+
+func defaultAlertRules() []AlertRule {
+	return []AlertRule{
+		{Name: "Low disk space", Kind: alertDiskFreeBelow, Threshold: 10, Enabled: true},
+		{Name: "High memory usage", Kind: alertMemoryAbove, Threshold: 90, ForSeconds: 300, Enabled: true},
+	}
+}
+
+func loadAlertRules() ([]AlertRule, error) {
+	data, err := os.ReadFile(alertRulesFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultAlertRules(), nil
+		}
+		return nil, err
+	}
+	var rules []AlertRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", alertRulesFilePath, err)
+	}
+	return rules, nil
+}
+
+func saveAlertRules(rules []AlertRule) error {
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(alertRulesFilePath, data, 0644)
+}
+
+func appendAlertLog(ev AlertEvent) error {
+	f, err := os.OpenFile(alertLogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", ev.Timestamp, ev.Rule, ev.Message)
+	return err
+}
+
+func loadAlertLog() []AlertEvent {
+	f, err := os.Open(alertLogFilePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var events []AlertEvent
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.SplitN(sc.Text(), "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		events = append(events, AlertEvent{Timestamp: parts[0], Rule: parts[1], Message: parts[2]})
+	}
+	return events
+}
+
+// ---------------------------------------------------------------------
+//  2) Alert Evaluation
+// ---------------------------------------------------------------------
+
+// AlertManager evaluates the rules against every sample from the SystemSampler.
+// A rule fires once when its condition has held for ForSeconds, and is re-armed
+// as soon as the condition clears.
+type AlertManager struct {
+	mu     sync.Mutex
+	rules  []AlertRule
+	since  map[string]time.Time // first time a condition was seen, per rule instance
+	firing map[string]bool
+	events []AlertEvent
+
+	onAlert func(AlertEvent)
+}
+
+func newAlertManager(rules []AlertRule) *AlertManager {
+	return &AlertManager{
+		rules:  rules,
+		since:  map[string]time.Time{},
+		firing: map[string]bool{},
+		events: loadAlertLog(),
+	}
+}
+
+func (am *AlertManager) getRules() []AlertRule {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	return append([]AlertRule{}, am.rules...)
+}
+
+// setRules replaces the rules with a copy of rules, so the caller may keep
+// editing its slice while the sampler evaluates.
+func (am *AlertManager) setRules(rules []AlertRule) error {
+	am.mu.Lock()
+	am.rules = append([]AlertRule{}, rules...)
+	am.since = map[string]time.Time{}
+	am.firing = map[string]bool{}
+	am.mu.Unlock()
+	return saveAlertRules(rules)
+}
+
+// watchesProcesses reports whether an enabled rule needs the process list.
+func (am *AlertManager) watchesProcesses() bool {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	for _, r := range am.rules {
+		if r.Enabled && r.Kind == alertProcessMissing {
+			return true
+		}
+	}
+	return false
+}
+
+func (am *AlertManager) getEvents() []AlertEvent {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	return append([]AlertEvent{}, am.events...)
+}
+
+// alertCheck is the outcome of one rule against one sample.
+type alertCheck struct {
+	key      string
+	breached bool
+	message  string
+}
+
+func checkRule(r AlertRule, sample SystemSample) []alertCheck {
+	switch r.Kind {
+	case alertDiskFreeBelow:
+		var checks []alertCheck
+		for _, d := range sample.Disks {
+			if r.Target != "" && !strings.EqualFold(d.Mount, r.Target) {
+				continue
+			}
+			checks = append(checks, alertCheck{
+				key:      r.Name + "|" + d.Mount,
+				breached: d.FreePercent < r.Threshold,
+				message: fmt.Sprintf("Disk %s has %.1f%% free (%.2f GB), below %.0f%%.",
+					d.Mount, d.FreePercent, float64(d.Free)/1e9, r.Threshold),
+			})
+		}
+		return checks
+	case alertMemoryAbove:
+		return []alertCheck{{
+			key:      r.Name,
+			breached: sample.MemPercent > r.Threshold,
+			message:  fmt.Sprintf("Memory usage is %.1f%%, above %.0f%%.", sample.MemPercent, r.Threshold),
+		}}
+	case alertCPUAbove:
+		return []alertCheck{{
+			key:      r.Name,
+			breached: sample.CPUPercent > r.Threshold,
+			message:  fmt.Sprintf("CPU usage is %.1f%%, above %.0f%%.", sample.CPUPercent, r.Threshold),
+		}}
+	case alertProcessMissing:
+		name := strings.ToLower(strings.TrimSpace(r.Target))
+		if name == "" || sample.Processes == nil {
+			return nil // no process list in this sample
+		}
+		return []alertCheck{{
+			key:      r.Name,
+			breached: !sample.Processes[name],
+			message:  fmt.Sprintf("Process %s is not running.", r.Target),
+		}}
+	}
+	return nil
+}
+
+// evaluate is subscribed to the sampler.
+func (am *AlertManager) evaluate(sample SystemSample) {
+	var fired []AlertEvent
+
+	am.mu.Lock()
+	for _, r := range am.rules {
+		if !r.Enabled {
+			continue
+		}
+		hold := time.Duration(r.ForSeconds) * time.Second
+		for _, c := range checkRule(r, sample) {
+			if !c.breached {
+				delete(am.since, c.key)
+				delete(am.firing, c.key)
+				continue
+			}
+			first, ok := am.since[c.key]
+			if !ok {
+				first = sample.Time
+				am.since[c.key] = first
+			}
+			if am.firing[c.key] || sample.Time.Sub(first) < hold {
+				continue
+			}
+			am.firing[c.key] = true
+			ev := AlertEvent{
+				Timestamp: sample.Time.Format("2006-01-02 15:04:05"),
+				Rule:      r.Name,
+				Message:   c.message,
+			}
+			am.events = append(am.events, ev)
+			fired = append(fired, ev)
+		}
+	}
+	onAlert := am.onAlert
+	am.mu.Unlock()
+
+	for _, ev := range fired {
+		if err := appendAlertLog(ev); err != nil {
+			fmt.Println("Error writing alert log:", err)
+		}
+		if a := fyne.CurrentApp(); a != nil {
+			a.SendNotification(fyne.NewNotification(ev.Rule, ev.Message))
+		}
+		if onAlert != nil {
+			onAlert(ev)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------
+//  3) Alerts UI
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) setupAlertsUI() fyne.CanvasObject {
+	rules := s.alerts.getRules()
+	selectedRule := -1
+
+	describe := func(r AlertRule) string {
+		state := "on"
+		if !r.Enabled {
+			state = "off"
+		}
+		var cond string
+		switch r.Kind {
+		case alertProcessMissing:
+			cond = fmt.Sprintf("%s: %s", r.Kind, r.Target)
+		case alertDiskFreeBelow:
+			target := r.Target
+			if target == "" {
+				target = "any disk"
+			}
+			cond = fmt.Sprintf("%s %.0f (%s)", r.Kind, r.Threshold, target)
+		default:
+			cond = fmt.Sprintf("%s %.0f", r.Kind, r.Threshold)
+		}
+		if r.ForSeconds > 0 {
+			cond += fmt.Sprintf(" for %ds", r.ForSeconds)
+		}
+		return fmt.Sprintf("[%s] %s — %s", state, r.Name, cond)
+	}
+
+	ruleList := widget.NewList(
+		func() int { return len(rules) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(describe(rules[id]))
+		},
+	)
+	ruleList.OnSelected = func(id widget.ListItemID) { selectedRule = id }
+	ruleList.OnUnselected = func(widget.ListItemID) { selectedRule = -1 }
+
+	saveRules := func() {
+		if err := s.alerts.setRules(rules); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+		}
+		ruleList.Refresh()
+	}
+
+	addBtn := s.commandButton(toolSystemInfo, "Add Rule", nil, func() {
+		nameEntry := widget.NewEntry()
+		kindSelect := widget.NewSelect(alertKinds, nil)
+		kindSelect.SetSelected(alertDiskFreeBelow)
+		thresholdEntry := widget.NewEntry()
+		thresholdEntry.SetText("10")
+		targetEntry := widget.NewEntry()
+		targetEntry.SetPlaceHolder("mount point or process name")
+		forEntry := widget.NewEntry()
+		forEntry.SetText("0")
+
+		dialog.ShowForm("Add Alert Rule", "Save", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Name", nameEntry),
+			widget.NewFormItem("Condition", kindSelect),
+			widget.NewFormItem("Threshold (%)", thresholdEntry),
+			widget.NewFormItem("Target", targetEntry),
+			widget.NewFormItem("For (seconds)", forEntry),
+		}, func(confirm bool) {
+			if !confirm {
+				return
+			}
+			name := strings.TrimSpace(nameEntry.Text)
+			if name == "" {
+				dialog.ShowInformation("Invalid Input", "Rule name cannot be empty.", s.mainWindow)
+				return
+			}
+			for _, r := range rules {
+				if r.Name == name {
+					dialog.ShowInformation("Invalid Input", "A rule named "+name+" already exists.", s.mainWindow)
+					return
+				}
+			}
+			threshold, err := strconv.ParseFloat(strings.TrimSpace(thresholdEntry.Text), 64)
+			if err != nil && kindSelect.Selected != alertProcessMissing {
+				dialog.ShowInformation("Invalid Input", "Threshold must be a number.", s.mainWindow)
+				return
+			}
+			forSecs, err := strconv.Atoi(strings.TrimSpace(forEntry.Text))
+			if err != nil || forSecs < 0 {
+				dialog.ShowInformation("Invalid Input", "Duration must be a whole number of seconds.", s.mainWindow)
+				return
+			}
+			target := strings.TrimSpace(targetEntry.Text)
+			if kindSelect.Selected == alertProcessMissing && target == "" {
+				dialog.ShowInformation("Invalid Input", "Enter the process name to watch.", s.mainWindow)
+				return
+			}
+			rules = append(rules, AlertRule{
+				Name:       name,
+				Kind:       kindSelect.Selected,
+				Threshold:  threshold,
+				Target:     target,
+				ForSeconds: forSecs,
+				Enabled:    true,
+			})
+			saveRules()
+		}, s.mainWindow)
+	})
+
+	toggleBtn := s.commandButton(toolSystemInfo, "Enable/Disable", nil, func() {
+		if selectedRule < 0 || selectedRule >= len(rules) {
+			dialog.ShowInformation("No Rule Selected", "Please select a rule.", s.mainWindow)
+			return
+		}
+		rules[selectedRule].Enabled = !rules[selectedRule].Enabled
+		saveRules()
+	})
+
+	removeBtn := s.commandButton(toolSystemInfo, "Remove Rule", nil, func() {
+		if selectedRule < 0 || selectedRule >= len(rules) {
+			dialog.ShowInformation("No Rule Selected", "Please select a rule.", s.mainWindow)
+			return
+		}
+		rules = append(rules[:selectedRule], rules[selectedRule+1:]...)
+		selectedRule = -1
+		ruleList.UnselectAll()
+		saveRules()
+	})
+
+	events := s.alerts.getEvents()
+	logList := widget.NewList(
+		func() int { return len(events) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			// newest first
+			ev := events[len(events)-1-id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s  %s: %s", ev.Timestamp, ev.Rule, ev.Message))
+		},
+	)
+	s.alerts.mu.Lock()
+	s.alerts.onAlert = func(AlertEvent) {
+		latest := s.alerts.getEvents()
+		s.postUI(func() {
+			events = latest
+			logList.Refresh()
+		})
+	}
+	s.alerts.mu.Unlock()
+
+	rulesBox := container.NewBorder(
+		container.NewHBox(widget.NewLabel("Alert Rules"), addBtn, toggleBtn, removeBtn, layout.NewSpacer()),
+		nil, nil, nil,
+		ruleList,
+	)
+	logBox := container.NewBorder(
+		widget.NewLabel("Alert Log"),
+		nil, nil, nil,
+		logList,
+	)
+
+	split := container.NewVSplit(rulesBox, logBox)
+	split.Offset = 0.4
+	return split
+}
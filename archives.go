@@ -0,0 +1,269 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+)
+
+// archiveSeparator joins an archive's path and the path of an entry inside
+// it, e.g. backup.zip!/photos/a.jpg
+const archiveSeparator = "!/"
+
+// Supported archive formats, matched on the lower-case file name
+const (
+	archiveZip   = "zip"
+	archiveTar   = "tar"
+	archiveTarGz = "tar.gz"
+	archiveTarBz = "tar.bz2"
+	archiveGzip  = "gz"
+	archiveBzip2 = "bz2"
+)
+
+var archiveSuffixes = []struct {
+	suffix string
+	format string
+}{
+	{".tar.gz", archiveTarGz},
+	{".tgz", archiveTarGz},
+	{".tar.bz2", archiveTarBz},
+	{".tbz2", archiveTarBz},
+	{".tar", archiveTar},
+	{".zip", archiveZip},
+	{".jar", archiveZip},
+	{".gz", archiveGzip},
+	{".bz2", archiveBzip2},
+}
+
+// archiveEntry is a file stored inside an archive.
+type archiveEntry struct {
+	Path string // virtual path, archive + archiveSeparator + name
+	Size int64
+	Hash string // hex hash with the scan's algorithm, as generateHash
+	SHA  string // hex SHA-256, if verification was asked for
+}
+
+// ---------------------------------------------------------------------
+//  1) Reading Archives
+// ---------------------------------------------------------------------
+
+// archiveFormat returns the format of the archive at p, or "" if p is not
+// an archive this scanner can read.
+func archiveFormat(p string) string {
+	lower := strings.ToLower(p)
+	for _, a := range archiveSuffixes {
+		if strings.HasSuffix(lower, a.suffix) {
+			return a.format
+		}
+	}
+	return ""
+}
+
+func archiveEntryPath(archive, name string) string {
+	return archive + archiveSeparator + strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// hashArchive hashes every non-empty file inside the archive at p with
+// algo, and with SHA-256 as well if verify is set. Nested archives are
+// listed as files, not opened.
+func hashArchive(p, algo string, verify bool) ([]archiveEntry, error) {
+	hasher, err := lookupHashAlgorithm(algo)
+	if err != nil {
+		return nil, err
+	}
+	hashEntry := func(name string, r io.Reader) (archiveEntry, error) {
+		h := hasher.newHash()
+		sha := sha256.New()
+		w := io.Writer(h)
+		if verify {
+			w = io.MultiWriter(h, sha)
+		}
+		n, err := io.Copy(w, r)
+		if err != nil {
+			return archiveEntry{}, fmt.Errorf("reading %s: %w", name, err)
+		}
+		e := archiveEntry{Path: name, Size: n, Hash: hex.EncodeToString(h.Sum(nil))}
+		if verify {
+			e.SHA = hex.EncodeToString(sha.Sum(nil))
+		}
+		return e, nil
+	}
+
+	if archiveFormat(p) == archiveZip {
+		return hashZip(p, hashEntry)
+	}
+	var entries []archiveEntry
+	err = walkStream(p, func(name string, r io.Reader) (bool, error) {
+		e, err := hashEntry(name, r)
+		if err != nil {
+			return false, err
+		}
+		if e.Size > 0 {
+			entries = append(entries, e)
+		}
+		return true, nil
+	})
+	return entries, err
+}
+
+// walkStream calls fn with every file of a tar or single-file archive,
+// which can only be read from start to end, until fn returns false.
+func walkStream(p string, fn func(name string, r io.Reader) (bool, error)) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	format := archiveFormat(p)
+	var r io.Reader = f
+	switch format {
+	case archiveTarGz, archiveGzip:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case archiveTarBz, archiveBzip2:
+		r = bzip2.NewReader(f)
+	case archiveTar:
+	default:
+		return fmt.Errorf("%s is not a supported archive", p)
+	}
+
+	switch format {
+	case archiveGzip, archiveBzip2:
+		// a single compressed file, named after the archive
+		name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		_, err := fn(archiveEntryPath(p, name), r)
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size == 0 {
+			continue
+		}
+		more, err := fn(archiveEntryPath(p, hdr.Name), tr)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// compareArchiveEntry calls compare with the content of the file at the
+// virtual path v inside archive.
+func compareArchiveEntry(archive, v string, compare func(r io.Reader) error) error {
+	if archiveFormat(archive) == archiveZip {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, zf := range zr.File {
+			if archiveEntryPath(archive, zf.Name) != v {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			return compare(rc)
+		}
+		return fmt.Errorf("%s is no longer in the archive", v)
+	}
+
+	found := false
+	err := walkStream(archive, func(name string, r io.Reader) (bool, error) {
+		if name != v {
+			return true, nil
+		}
+		found = true
+		return false, compare(r)
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("%s is no longer in the archive", v)
+	}
+	return err
+}
+
+func hashZip(p string, hashEntry func(string, io.Reader) (archiveEntry, error)) ([]archiveEntry, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var entries []archiveEntry
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || zf.UncompressedSize64 == 0 {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return entries, err
+		}
+		e, err := hashEntry(archiveEntryPath(p, zf.Name), rc)
+		rc.Close()
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// ---------------------------------------------------------------------
+//  2) Selections With Archive Entries
+// ---------------------------------------------------------------------
+
+// archivedPaths maps the virtual path of every listed file stored inside an
+// archive to that archive. Such files can only be compared, not deleted,
+// renamed or linked.
+func (s *FileScanner) archivedPaths() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make(map[string]string)
+	for _, fi := range s.allFileItems {
+		if fi.archive != "" {
+			res[fi.filePath] = fi.archive
+		}
+	}
+	return res
+}
+
+// withoutArchiveEntries drops virtual archive paths from a selection and
+// tells the user why. ok is false when nothing is left.
+func (s *FileScanner) withoutArchiveEntries(paths []string) (res []string, ok bool) {
+	archived := s.archivedPaths()
+	for _, p := range paths {
+		if archived[p] == "" {
+			res = append(res, p)
+		}
+	}
+	if skipped := len(paths) - len(res); skipped > 0 {
+		dialog.ShowInformation("Files Inside Archives", fmt.Sprintf(
+			"%d selected file(s) are stored inside archives and were left out.\nTo remove them, delete or repack the archive itself.", skipped), s.mainWindow)
+	}
+	return res, len(res) > 0
+}
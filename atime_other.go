@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// fileAccessTime falls back to the modification time on this platform.
+func fileAccessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
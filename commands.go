@@ -0,0 +1,327 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Tool names, as shown in the left menu and the command palette
+const (
+	toolDuplicateFinder = "Duplicate Finder"
+	toolSpaceCleaner    = "Space Cleaner"
+	toolHistory         = "Deletion History"
+	toolPasswordManager = "Password Manager"
+	toolSystemInfo      = "System Info"
+	toolStartup         = "Startup Items"
+	toolJobs            = "Scheduled Jobs"
+	toolSettings        = "Settings"
+)
+
+// ---------------------------------------------------------------------
+//  1) Commands
+// ---------------------------------------------------------------------
+
+// Command is an action listed in the command palette. A command that
+// belongs to a tool switches to it before running, and its shortcut only
+// fires while that tool is shown.
+type Command struct {
+	Tool     string // "" for commands that work anywhere
+	Name     string
+	Shortcut fyne.Shortcut // nil if the command has no shortcut
+	Run      func()
+}
+
+func (c *Command) title() string {
+	if c.Tool == "" {
+		return c.Name
+	}
+	return c.Tool + ": " + c.Name
+}
+
+// keyShortcut is a shortcut without modifiers. The canvas only sees those
+// when no widget has focus, so it is used for Escape alone.
+type keyShortcut fyne.KeyName
+
+func (k keyShortcut) ShortcutName() string { return "Key+" + string(k) }
+
+// Shortcuts shared by the tools; each tool binds them to its own buttons
+var (
+	shortcutPalette    = ctrlKey(fyne.KeyP, fyne.KeyModifierShift)
+	shortcutScan       = ctrlKey(fyne.KeyR)
+	shortcutCancelScan = keyShortcut(fyne.KeyEscape)
+	shortcutSelectAll  = &fyne.ShortcutSelectAll{}
+	shortcutSelectNone = ctrlKey(fyne.KeyA, fyne.KeyModifierShift)
+	shortcutDelete     = ctrlKey(fyne.KeyDelete)
+	shortcutNextPage   = ctrlKey(fyne.KeyPageDown)
+	shortcutPrevPage   = ctrlKey(fyne.KeyPageUp)
+)
+
+// ctrlKey is Ctrl+key (Cmd+key on macOS) plus any extra modifiers.
+func ctrlKey(key fyne.KeyName, extra ...fyne.KeyModifier) *desktop.CustomShortcut {
+	mod := fyne.KeyModifierShortcutDefault
+	for _, m := range extra {
+		mod |= m
+	}
+	return &desktop.CustomShortcut{KeyName: key, Modifier: mod}
+}
+
+// shortcutLabel formats a shortcut for display, e.g. "Ctrl+Shift+P".
+func shortcutLabel(sc fyne.Shortcut) string {
+	switch v := sc.(type) {
+	case keyShortcut:
+		if fyne.KeyName(v) == fyne.KeyEscape {
+			return "Esc"
+		}
+		return string(v)
+	case *fyne.ShortcutSelectAll:
+		return shortcutLabel(ctrlKey(fyne.KeyA))
+	case *desktop.CustomShortcut:
+		var parts []string
+		if v.Modifier&fyne.KeyModifierControl != 0 {
+			parts = append(parts, "Ctrl")
+		}
+		if v.Modifier&fyne.KeyModifierSuper != 0 {
+			parts = append(parts, "Cmd")
+		}
+		if v.Modifier&fyne.KeyModifierAlt != 0 {
+			parts = append(parts, "Alt")
+		}
+		if v.Modifier&fyne.KeyModifierShift != 0 {
+			parts = append(parts, "Shift")
+		}
+		key := string(v.KeyName)
+		switch v.KeyName {
+		case fyne.KeyPageDown:
+			key = "PgDn"
+		case fyne.KeyPageUp:
+			key = "PgUp"
+		}
+		return strings.Join(append(parts, key), "+")
+	}
+	return ""
+}
+
+// addCommand registers a command, replacing one with the same title (tool
+// panes that are rebuilt register their commands again).
+func (s *FileScanner) addCommand(c *Command) {
+	for i, old := range s.commands {
+		if old.title() == c.title() {
+			s.commands[i] = c
+			return
+		}
+	}
+	s.commands = append(s.commands, c)
+	if c.Shortcut != nil {
+		s.bindShortcut(c.Shortcut)
+	}
+}
+
+// commandButton creates a tool button that is also listed as a command.
+func (s *FileScanner) commandButton(tool, label string, sc fyne.Shortcut, tapped func()) *widget.Button {
+	btn := widget.NewButton(label, tapped)
+	s.addCommand(&Command{Tool: tool, Name: label, Shortcut: sc, Run: func() {
+		if !btn.Disabled() {
+			btn.OnTapped()
+		}
+	}})
+	return btn
+}
+
+// bindShortcut makes the window dispatch sc to the command bound to it in
+// the current tool.
+func (s *FileScanner) bindShortcut(sc fyne.Shortcut) {
+	name := sc.ShortcutName()
+	if s.boundShortcuts[name] {
+		return
+	}
+	if s.boundShortcuts == nil {
+		s.boundShortcuts = make(map[string]bool)
+	}
+	s.boundShortcuts[name] = true
+
+	c := s.mainWindow.Canvas()
+	if key, ok := sc.(keyShortcut); ok {
+		prev := c.OnTypedKey()
+		c.SetOnTypedKey(func(ev *fyne.KeyEvent) {
+			if ev.Name == fyne.KeyName(key) {
+				s.runShortcut(name)
+			}
+			if prev != nil {
+				prev(ev)
+			}
+		})
+		return
+	}
+	c.AddShortcut(sc, func(fyne.Shortcut) {
+		s.runShortcut(name)
+	})
+}
+
+// runShortcut runs the command bound to the shortcut in the current tool,
+// falling back to a global command.
+func (s *FileScanner) runShortcut(name string) {
+	tool := s.currentTool()
+	var global *Command
+	for _, c := range s.commands {
+		if c.Shortcut == nil || c.Shortcut.ShortcutName() != name {
+			continue
+		}
+		if c.Tool == tool {
+			c.Run()
+			return
+		}
+		if c.Tool == "" && global == nil {
+			global = c
+		}
+	}
+	if global != nil {
+		global.Run()
+	}
+}
+
+// runCommand switches to the command's tool and runs it.
+func (s *FileScanner) runCommand(c *Command) {
+	if c.Tool == "" || c.Tool == s.currentTool() {
+		c.Run()
+		return
+	}
+	s.showTool(c.Tool)
+	// showing a tool may rebuild it, so look the command up again
+	title := c.title()
+	for _, cur := range s.commands {
+		if cur.title() == title {
+			cur.Run()
+			return
+		}
+	}
+}
+
+// addPageCommands lets the keyboard scroll a tool's file list a screen at a time.
+func (s *FileScanner) addPageCommands(tool string, sl *SelectableList) {
+	s.addCommand(&Command{Tool: tool, Name: "Next Page", Shortcut: shortcutNextPage, Run: func() { sl.page(1) }})
+	s.addCommand(&Command{Tool: tool, Name: "Previous Page", Shortcut: shortcutPrevPage, Run: func() { sl.page(-1) }})
+}
+
+// ---------------------------------------------------------------------
+//  2) Tools
+// ---------------------------------------------------------------------
+
+// toolEntry is one tool in the left menu. Ctrl+key switches to it.
+type toolEntry struct {
+	name string
+	key  fyne.KeyName
+	root *fyne.CanvasObject // the FileScanner field holding the tool's pane
+	show func()             // nil shows *root as it is
+}
+
+// currentTool returns the name of the tool shown on the right.
+func (s *FileScanner) currentTool() string {
+	if s.split == nil {
+		return ""
+	}
+	for _, t := range s.tools {
+		if *t.root == s.split.Trailing {
+			return t.name
+		}
+	}
+	return ""
+}
+
+func (s *FileScanner) showTool(name string) {
+	for _, t := range s.tools {
+		if t.name != name {
+			continue
+		}
+		if t.show != nil {
+			t.show()
+		} else {
+			s.switchRightContent(*t.root)
+		}
+		return
+	}
+}
+
+// ---------------------------------------------------------------------
+//  3) Command Palette
+// ---------------------------------------------------------------------
+
+// showCommandPalette lists every command. Typing filters the list; Enter
+// runs the first match.
+func (s *FileScanner) showCommandPalette() {
+	var matches []*Command
+	filter := func(text string) {
+		words := strings.Fields(strings.ToLower(text))
+		matches = nil
+		for _, c := range s.commands {
+			title := strings.ToLower(c.title())
+			ok := true
+			for _, w := range words {
+				if !strings.Contains(title, w) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				matches = append(matches, c)
+			}
+		}
+	}
+	filter("")
+
+	var dlg *dialog.CustomDialog
+	run := func(c *Command) {
+		dlg.Hide()
+		s.runCommand(c)
+	}
+
+	list := widget.NewList(
+		func() int { return len(matches) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil, widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := obj.(*fyne.Container)
+			c := matches[id]
+			row.Objects[0].(*widget.Label).SetText(c.title())
+			keys := ""
+			if c.Shortcut != nil {
+				keys = shortcutLabel(c.Shortcut)
+			}
+			row.Objects[1].(*widget.Label).SetText(keys)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		run(matches[id])
+	}
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Type a command...")
+	entry.OnChanged = func(text string) {
+		filter(text)
+		list.UnselectAll()
+		list.Refresh()
+		list.ScrollToTop()
+	}
+	entry.OnSubmitted = func(string) {
+		if len(matches) > 0 {
+			run(matches[0])
+		}
+	}
+
+	content := container.NewBorder(entry, nil, nil, nil, list)
+	dlg = dialog.NewCustom("Command Palette", "Close", content, s.mainWindow)
+	dlg.Resize(fyne.NewSize(600, 450))
+	dlg.Show()
+	s.mainWindow.Canvas().Focus(entry)
+}
+
+// addGlobalCommands registers the commands that work in every tool.
+func (s *FileScanner) addGlobalCommands() {
+	s.addCommand(&Command{Name: "Command Palette", Shortcut: shortcutPalette, Run: s.showCommandPalette})
+	s.addCommand(&Command{Name: "Cancel Scan", Shortcut: shortcutCancelScan, Run: s.cancelScan})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ---------------------------------------------------------------------
+//  1) Keyboard Modifiers
+// ---------------------------------------------------------------------
+
+// keyModifiers tracks whether Shift is held in the main window, so clicks and
+// the space bar in a SelectableList can select a range.
+type keyModifiers struct {
+	mu    sync.Mutex
+	shift bool
+}
+
+func trackKeyModifiers(w fyne.Window) *keyModifiers {
+	km := &keyModifiers{}
+	if dc, ok := w.Canvas().(desktop.Canvas); ok {
+		dc.SetOnKeyDown(func(ev *fyne.KeyEvent) { km.set(ev.Name, true) })
+		dc.SetOnKeyUp(func(ev *fyne.KeyEvent) { km.set(ev.Name, false) })
+	}
+	return km
+}
+
+func (km *keyModifiers) set(key fyne.KeyName, down bool) {
+	if key != desktop.KeyShiftLeft && key != desktop.KeyShiftRight {
+		return
+	}
+	km.mu.Lock()
+	km.shift = down
+	km.mu.Unlock()
+}
+
+func (km *keyModifiers) shiftHeld() bool {
+	if km == nil {
+		return false
+	}
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	return km.shift
+}
+
+// ---------------------------------------------------------------------
+//  2) Selectable List
+// ---------------------------------------------------------------------
+
+// SelectableList is a virtualised list of checkable rows. Only the visible
+// rows have widgets; whether a row is checked is kept in the data model and
+// read through isSelected/setSelected, so the list scales to any length.
+//
+// Clicking a row or pressing Space toggles it. With Shift held, every row
+// between the last toggled row and this one takes the last row's state.
+// The arrow keys move between rows once the list has focus.
+type SelectableList struct {
+	List *widget.List
+
+	length      func() int
+	label       func(i int) string
+	isSelected  func(i int) bool
+	setSelected func(i int, selected bool)
+	mods        *keyModifiers
+	anchor      int
+
+	// OnRowToggled is called with the row that was clicked or toggled with Space.
+	OnRowToggled func(i int)
+
+	// OnSelectionChanged is called after rows are checked or unchecked.
+	OnSelectionChanged func()
+}
+
+func newSelectableList(mods *keyModifiers, length func() int, label func(int) string,
+	isSelected func(int) bool, setSelected func(int, bool)) *SelectableList {
+	sl := &SelectableList{
+		length:      length,
+		label:       label,
+		isSelected:  isSelected,
+		setSelected: setSelected,
+		mods:        mods,
+		anchor:      -1,
+	}
+	sl.List = widget.NewList(
+		length,
+		func() fyne.CanvasObject {
+			lbl := widget.NewLabel("")
+			lbl.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.CheckButtonIcon()), nil, lbl)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := obj.(*fyne.Container)
+			lbl := row.Objects[0].(*widget.Label)
+			icon := row.Objects[1].(*widget.Icon)
+			if sl.isSelected(id) {
+				icon.SetResource(theme.CheckButtonCheckedIcon())
+			} else {
+				icon.SetResource(theme.CheckButtonIcon())
+			}
+			lbl.SetText(sl.label(id))
+		},
+	)
+	sl.List.OnSelected = func(id widget.ListItemID) {
+		sl.toggle(id)
+		sl.List.Unselect(id)
+	}
+	return sl
+}
+
+func (sl *SelectableList) toggle(id int) {
+	if sl.mods.shiftHeld() && sl.anchor >= 0 && sl.anchor < sl.length() {
+		state := sl.isSelected(sl.anchor)
+		from, to := sl.anchor, id
+		if from > to {
+			from, to = to, from
+		}
+		for i := from; i <= to; i++ {
+			sl.setSelected(i, state)
+		}
+	} else {
+		sl.setSelected(id, !sl.isSelected(id))
+		sl.anchor = id
+	}
+	sl.List.Refresh()
+	if sl.OnRowToggled != nil {
+		sl.OnRowToggled(id)
+	}
+	if sl.OnSelectionChanged != nil {
+		sl.OnSelectionChanged()
+	}
+}
+
+// setAll checks or unchecks every row.
+func (sl *SelectableList) setAll(selected bool) {
+	for i := 0; i < sl.length(); i++ {
+		sl.setSelected(i, selected)
+	}
+	sl.List.Refresh()
+	if sl.OnSelectionChanged != nil {
+		sl.OnSelectionChanged()
+	}
+}
+
+// Refresh redraws the rows after the underlying data changed.
+func (sl *SelectableList) Refresh() {
+	sl.anchor = -1
+	sl.List.Refresh()
+	if sl.OnSelectionChanged != nil {
+		sl.OnSelectionChanged()
+	}
+}
+
+// page scrolls the list by n screens (negative scrolls up).
+func (sl *SelectableList) page(n int) {
+	sl.List.ScrollToOffset(sl.List.GetScrollOffset() + float32(n)*sl.List.Size().Height)
+}
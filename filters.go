@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------
+//  1) File Filters
+// ---------------------------------------------------------------------
+
+// FileFilter decides which scanned files the Duplicate Finder and the Space
+// Cleaner list. Zero values mean "no restriction".
+type FileFilter struct {
+	MinSize         int64
+	MaxSize         int64 // 0 = no limit
+	NotModifiedDays int   // 0 = any age
+	NotAccessedDays int   // 0 = any age
+	ModifiedAfter   time.Time
+	ModifiedBefore  time.Time // the whole day counts
+	IncludePatterns []string
+	ExcludePatterns []string
+	Extensions      []string // lower case with the dot; empty = any
+	ExcludeDirs     []string // folder names (globs) that are not entered
+	SkipHidden      bool
+	SkipSystem      bool
+}
+
+const defaultMinSizeMB = 10
+
+const (
+	regexPrefix = "re:"        // marks a pattern as a regular expression
+	dateLayout  = "2006-01-02" // dates typed in filter forms
+)
+
+// parseFileFilter builds a filter from the basic form fields shared by the
+// Space Cleaner and scheduled jobs. Empty fields mean "no restriction".
+func parseFileFilter(minSizeMB, notModifiedDays, notAccessedDays, include, exclude string) (FileFilter, error) {
+	var f FileFilter
+	var err error
+	if f.MinSize, err = parseSizeMB(minSizeMB, "minimum"); err != nil {
+		return f, err
+	}
+	if v := strings.TrimSpace(notModifiedDays); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 {
+			return f, fmt.Errorf("modified age must be a whole number of days")
+		}
+		f.NotModifiedDays = d
+	}
+	if v := strings.TrimSpace(notAccessedDays); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 {
+			return f, fmt.Errorf("accessed age must be a whole number of days")
+		}
+		f.NotAccessedDays = d
+	}
+	f.IncludePatterns = splitPatterns(include)
+	f.ExcludePatterns = splitPatterns(exclude)
+	for _, p := range append(f.IncludePatterns, f.ExcludePatterns...) {
+		if err := validatePattern(p); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+func parseSizeMB(text, which string) (int64, error) {
+	v := strings.TrimSpace(text)
+	if v == "" {
+		return 0, nil
+	}
+	mb, err := strconv.ParseFloat(v, 64)
+	if err != nil || mb < 0 {
+		return 0, fmt.Errorf("%s size must be a positive number of MB", which)
+	}
+	return int64(mb * 1048576), nil
+}
+
+func parseDate(text, which string) (time.Time, error) {
+	v := strings.TrimSpace(text)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, v, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("%s date must look like %s", which, dateLayout)
+	}
+	return t, nil
+}
+
+func splitPatterns(text string) []string {
+	var res []string
+	for _, part := range strings.Split(text, ",") {
+		if trim := strings.TrimSpace(part); trim != "" {
+			res = append(res, trim)
+		}
+	}
+	return res
+}
+
+func validatePattern(p string) error {
+	if expr, ok := strings.CutPrefix(p, regexPrefix); ok {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid regular expression %q: %v", expr, err)
+		}
+		return nil
+	}
+	if _, err := path.Match(filepath.ToSlash(p), ""); err != nil {
+		return fmt.Errorf("invalid pattern %q", p)
+	}
+	return nil
+}
+
+var compiledPatterns sync.Map // regexp source -> *regexp.Regexp
+
+// matchPattern matches a glob or, with the re: prefix, a case-insensitive
+// regular expression searched anywhere in the path (forward slashes). An
+// invalid pattern is an error, so callers can fail closed.
+func matchPattern(pattern, p string) (bool, error) {
+	expr, ok := strings.CutPrefix(pattern, regexPrefix)
+	if !ok {
+		if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+			return false, err
+		}
+		return matchGlob(pattern, p), nil
+	}
+	re, found := compiledPatterns.Load(expr)
+	if !found {
+		c, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return false, err
+		}
+		re, _ = compiledPatterns.LoadOrStore(expr, c)
+	}
+	return re.(*regexp.Regexp).MatchString(filepath.ToSlash(p)), nil
+}
+
+// matchGlob matches patterns without a separator against the file name, and
+// patterns with one against the end of the path, so */cache/* matches a
+// file in any cache folder. Both use forward slashes and path.Match, where
+// * never crosses a separator, so patterns behave the same everywhere.
+func matchGlob(pattern, p string) bool {
+	pattern = strings.ToLower(filepath.ToSlash(pattern))
+	p = strings.ToLower(filepath.ToSlash(p))
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	for i := 0; ; {
+		if ok, _ := path.Match(pattern, p[i:]); ok {
+			return true
+		}
+		j := strings.Index(p[i:], "/")
+		if j < 0 {
+			return false
+		}
+		i += j + 1
+	}
+}
+
+func (f FileFilter) matches(path string, info os.FileInfo, now time.Time) bool {
+	if !f.sizeOK(info.Size()) {
+		return false
+	}
+	if f.NotModifiedDays > 0 && now.Sub(info.ModTime()) < time.Duration(f.NotModifiedDays)*24*time.Hour {
+		return false
+	}
+	if f.NotAccessedDays > 0 && now.Sub(fileAccessTime(info)) < time.Duration(f.NotAccessedDays)*24*time.Hour {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && info.ModTime().Before(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !info.ModTime().Before(f.ModifiedBefore.AddDate(0, 0, 1)) {
+		return false
+	}
+	if (f.SkipHidden && isHiddenFile(path, info)) || (f.SkipSystem && isSystemFile(info)) {
+		return false
+	}
+	return f.matchesPath(path)
+}
+
+func (f FileFilter) sizeOK(size int64) bool {
+	return size >= f.MinSize && (f.MaxSize == 0 || size <= f.MaxSize)
+}
+
+// matchesPath applies the rules that only need a path: extensions and
+// patterns. Files inside archives are filtered with it.
+func (f FileFilter) matchesPath(path string) bool {
+	if len(f.Extensions) > 0 && !contains(f.Extensions, strings.ToLower(filepath.Ext(path))) {
+		return false
+	}
+	if len(f.IncludePatterns) > 0 {
+		included := false
+		for _, p := range f.IncludePatterns {
+			if ok, err := matchPattern(p, path); ok && err == nil {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, p := range f.ExcludePatterns {
+		// an exclude that cannot be read protects everything
+		if ok, err := matchPattern(p, path); ok || err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// skipDir reports whether a walk should not enter the folder at path.
+func (f FileFilter) skipDir(path string, info os.FileInfo) bool {
+	name := strings.ToLower(info.Name())
+	for _, d := range f.ExcludeDirs {
+		if ok, _ := filepath.Match(strings.ToLower(d), name); ok {
+			return true
+		}
+	}
+	return (f.SkipHidden && isHiddenFile(path, info)) || (f.SkipSystem && isSystemFile(info))
+}
+
+// folderRules returns only the rules that prune folders, for scans that
+// need every file of the folders they enter.
+func (f FileFilter) folderRules() FileFilter {
+	return FileFilter{ExcludeDirs: f.ExcludeDirs, SkipHidden: f.SkipHidden, SkipSystem: f.SkipSystem}
+}
+
+// ---------------------------------------------------------------------
+//  2) File Categories
+// ---------------------------------------------------------------------
+
+const (
+	categoryVideo      = "Video"
+	categoryArchives   = "Archives"
+	categoryInstallers = "Installers"
+	categoryLogs       = "Logs"
+	categoryCaches     = "Caches"
+	categoryOther      = "Other"
+)
+
+var fileCategories = []string{categoryVideo, categoryArchives, categoryInstallers, categoryLogs, categoryCaches, categoryOther}
+
+var categoryExtensions = map[string]string{
+	".mp4": categoryVideo, ".mkv": categoryVideo, ".avi": categoryVideo, ".mov": categoryVideo,
+	".wmv": categoryVideo, ".flv": categoryVideo, ".webm": categoryVideo, ".m4v": categoryVideo,
+	".mpg": categoryVideo, ".mpeg": categoryVideo,
+
+	".zip": categoryArchives, ".rar": categoryArchives, ".7z": categoryArchives, ".tar": categoryArchives,
+	".gz": categoryArchives, ".tgz": categoryArchives, ".bz2": categoryArchives, ".xz": categoryArchives,
+	".zst": categoryArchives,
+
+	".exe": categoryInstallers, ".msi": categoryInstallers, ".msix": categoryInstallers, ".iso": categoryInstallers,
+	".dmg": categoryInstallers, ".pkg": categoryInstallers, ".deb": categoryInstallers, ".rpm": categoryInstallers,
+	".appimage": categoryInstallers,
+
+	".log": categoryLogs, ".etl": categoryLogs, ".dmp": categoryLogs, ".evtx": categoryLogs,
+
+	".tmp": categoryCaches, ".cache": categoryCaches,
+}
+
+// fileCategory groups a file by extension, or by living under a cache directory.
+func fileCategory(path string) string {
+	if c, ok := categoryExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return c
+	}
+	for _, part := range strings.Split(filepath.ToSlash(strings.ToLower(filepath.Dir(path))), "/") {
+		if part == "cache" || part == ".cache" || part == "caches" || part == "inetcache" || part == "temp" || part == "tmp" {
+			return categoryCaches
+		}
+	}
+	return categoryOther
+}
+
+type categoryTotal struct {
+	Count int
+	Size  int64
+}
+
+func categoryTotals(items []*LargeFileItem) map[string]categoryTotal {
+	totals := map[string]categoryTotal{}
+	for _, lf := range items {
+		t := totals[lf.category]
+		t.Count++
+		t.Size += lf.size
+		totals[lf.category] = t
+	}
+	return totals
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.2f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.2f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.2f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
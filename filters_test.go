@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.log", "/var/log/app.LOG", true},
+		{"*.log", "/var/log/app.txt", false},
+		{"*/cache/*", "/home/user/.app/cache/data.bin", true},
+		{"*/cache/*", "/home/user/cache/sub/data.bin", false},
+		{"*/cache/*/*", "/home/user/cache/sub/data.bin", true},
+		{"*/node_modules/*", "/src/app/node_modules/index.js", true},
+		{"/home/*/notes.txt", "/home/user/notes.txt", true},
+		{"/home/*/notes.txt", "/home/user/docs/notes.txt", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesPathInvalidPattern(t *testing.T) {
+	const p = "/home/user/keep/notes.txt"
+	if (FileFilter{ExcludePatterns: []string{"re:keep/("}}).matchesPath(p) {
+		t.Error("an invalid exclude pattern let a file through")
+	}
+	if (FileFilter{IncludePatterns: []string{"[notes"}}).matchesPath(p) {
+		t.Error("an invalid include pattern let a file through")
+	}
+	if _, err := parseCleanupProfile([]byte(`{"Name": "x", "Targets": [{"Path": "/tmp", "Exclude": ["re:("]}]}`)); err == nil {
+		t.Error("profile with an invalid regular expression loaded without error")
+	}
+}
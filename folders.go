@@ -0,0 +1,751 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	folderGroupPrefix    = "folder:" // allDuplicates keys of duplicate folder groups
+	defaultFolderOverlap = 90        // Percent of content two folders must share
+	minFolderFiles       = 2         // Smaller folders are not reported on their own
+	maxFolderComparisons = 200000    // Near-identical candidate pairs checked per scan
+	folderProgressUpdate = 50        // Update the progress label every n hashed files
+)
+
+const nearFolderGroupPrefix = folderGroupPrefix + "near:" // allDuplicates keys of near-identical folder groups
+
+var errScanCancelled = errors.New("scan cancelled")
+
+// ---------------------------------------------------------------------
+//  1) Folder Tree
+// ---------------------------------------------------------------------
+
+// folderNode is one directory of a scanned tree. shape and content are
+// Merkle-style hashes over the whole subtree: shape covers names and sizes
+// and is cheap; content covers file hashes and is only computed for
+// folders whose shape matches another folder's.
+type folderNode struct {
+	path   string
+	parent *folderNode
+	depth  int
+	files  map[string]int64 // name -> size
+	dirs   map[string]*folderNode
+	bytes  int64 // total size of the subtree
+	count  int   // number of files in the subtree
+	shape  string
+
+	partial bool // the subtree holds entries the scan left out
+
+	content string
+	flat    map[string]string // relative path -> full path, built on demand
+}
+
+// buildFolderTree walks root and returns every directory, parents first,
+// and the paths it could not read. Folders and files that filter's folder
+// rules leave out are skipped, and the folders holding them marked partial.
+func buildFolderTree(root string, filter FileFilter, opts WalkOptions) ([]*folderNode, []SkippedPath, error) {
+	filter = filter.folderRules()
+	now := time.Now()
+	byPath := make(map[string]*folderNode)
+	var nodes []*folderNode
+	skipped, err := walkTree(root, opts, func(p string, info os.FileInfo) error {
+		if info.IsDir() {
+			if p != root && filter.skipDir(p, info) {
+				if parent := byPath[filepath.Dir(p)]; parent != nil {
+					parent.partial = true
+				}
+				return filepath.SkipDir
+			}
+			n := &folderNode{path: p, files: map[string]int64{}, dirs: map[string]*folderNode{}}
+			if parent := byPath[filepath.Dir(p)]; parent != nil && p != root {
+				n.parent, n.depth = parent, parent.depth+1
+				parent.dirs[filepath.Base(p)] = n
+			}
+			byPath[p] = n
+			nodes = append(nodes, n)
+			return nil
+		}
+		parent := byPath[filepath.Dir(p)]
+		if parent == nil {
+			return nil
+		}
+		if !filter.matches(p, info, now) {
+			parent.partial = true
+			return nil
+		}
+		parent.files[filepath.Base(p)] = info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, sk := range skipped {
+		if parent := byPath[filepath.Dir(sk.Path)]; parent != nil {
+			parent.partial = true
+		}
+		if n := byPath[sk.Path]; n != nil {
+			n.partial = true // a folder that could not be listed
+		}
+	}
+
+	// children come after their parents, so go backwards
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		var lines []string
+		for name, size := range n.files {
+			n.bytes += size
+			n.count++
+			lines = append(lines, fmt.Sprintf("f %s %d", name, size))
+		}
+		for name, c := range n.dirs {
+			n.bytes += c.bytes
+			n.count += c.count
+			n.partial = n.partial || c.partial
+			lines = append(lines, fmt.Sprintf("d %s %s", name, c.shape))
+		}
+		n.shape = hashLines(lines)
+	}
+	return nodes, skipped, nil
+}
+
+func hashLines(lines []string) string {
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, l := range lines {
+		h.Write([]byte(l))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fileHasher hashes file contents once per scan.
+type fileHasher struct {
+	s         *FileScanner
+	algo      string
+	cache     map[string]string
+	hashed    int
+	cancelled func() bool
+	progress  func(hashed int)
+}
+
+func (fh *fileHasher) hash(path string) (string, error) {
+	if h, ok := fh.cache[path]; ok {
+		return h, nil
+	}
+	if fh.cancelled != nil && fh.cancelled() {
+		return "", errScanCancelled
+	}
+	h, err := fh.s.generateHash(path, fh.algo)
+	if err != nil {
+		if st, e := os.Stat(path); e == nil && st.Size() == 0 {
+			h, err = "empty", nil // generateHash refuses empty files
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	fh.cache[path] = h
+	fh.hashed++
+	if fh.progress != nil && fh.hashed%folderProgressUpdate == 0 {
+		fh.progress(fh.hashed)
+	}
+	return h, nil
+}
+
+// contentHash hashes the names and file contents of n's subtree.
+func (n *folderNode) contentHash(fh *fileHasher) (string, error) {
+	if n.content != "" {
+		return n.content, nil
+	}
+	var lines []string
+	for name := range n.files {
+		h, err := fh.hash(filepath.Join(n.path, name))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, "f "+name+" "+h)
+	}
+	for name, c := range n.dirs {
+		h, err := c.contentHash(fh)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, "d "+name+" "+h)
+	}
+	n.content = hashLines(lines)
+	return n.content, nil
+}
+
+// flatten returns every file of the subtree by its path relative to n.
+func (n *folderNode) flatten() map[string]string {
+	if n.flat != nil {
+		return n.flat
+	}
+	n.flat = make(map[string]string)
+	for name := range n.files {
+		n.flat[name] = filepath.Join(n.path, name)
+	}
+	for name, c := range n.dirs {
+		for rel, full := range c.flatten() {
+			n.flat[filepath.Join(name, rel)] = full
+		}
+	}
+	return n.flat
+}
+
+func (n *folderNode) isAncestorOf(o *folderNode) bool {
+	for p := o.parent; p != nil; p = p.parent {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------------------------------------------------------------
+//  2) Duplicate Folders
+// ---------------------------------------------------------------------
+
+// FolderGroup is a set of folders with the same (Overlap 1) or largely the
+// same content.
+type FolderGroup struct {
+	Folders []*folderNode
+	Overlap float64
+}
+
+// folderOverlap is the share of bytes of the larger folder that exist in
+// the other at the same relative path with the same content.
+func folderOverlap(a, b *folderNode, fh *fileHasher) (float64, error) {
+	fa, fb := a.flatten(), b.flatten()
+	if len(fb) < len(fa) {
+		fa, fb = fb, fa
+	}
+	var shared int64
+	for rel, pa := range fa {
+		pb, ok := fb[rel]
+		if !ok {
+			continue
+		}
+		sa, sb := fileSize(pa), fileSize(pb)
+		if sa != sb {
+			continue
+		}
+		ha, err := fh.hash(pa)
+		if err != nil {
+			return 0, err
+		}
+		hb, err := fh.hash(pb)
+		if err != nil {
+			return 0, err
+		}
+		if ha == hb {
+			shared += sa
+		}
+	}
+	return float64(shared) / float64(max(a.bytes, b.bytes, 1)), nil
+}
+
+// findDuplicateFolders reports identical folders and, if minOverlap < 1,
+// folders sharing at least that share of their content. A group is left
+// out when its folders are all inside folders already reported.
+func findDuplicateFolders(nodes []*folderNode, minOverlap float64, fh *fileHasher) ([]FolderGroup, error) {
+	var groups []FolderGroup
+	reported := make(map[*folderNode]bool)
+	covered := func(n *folderNode) bool {
+		for p := n; p != nil; p = p.parent {
+			if reported[p] {
+				return true
+			}
+		}
+		return false
+	}
+	report := func(g FolderGroup) {
+		uncovered := 0
+		for _, f := range g.Folders {
+			if !covered(f) {
+				uncovered++
+			}
+		}
+		if uncovered == 0 {
+			return
+		}
+		for _, f := range g.Folders {
+			reported[f] = true
+		}
+		groups = append(groups, g)
+	}
+
+	// identical trees: same shape first, then the same content
+	byShape := make(map[string][]*folderNode)
+	for _, n := range nodes {
+		if n.count >= minFolderFiles {
+			byShape[n.shape] = append(byShape[n.shape], n)
+		}
+	}
+	var identical []FolderGroup
+	for _, same := range byShape {
+		if len(same) < 2 {
+			continue
+		}
+		byContent := make(map[string][]*folderNode)
+		var order []string
+		for _, n := range same {
+			h, err := n.contentHash(fh)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := byContent[h]; !ok {
+				order = append(order, h)
+			}
+			byContent[h] = append(byContent[h], n)
+		}
+		for _, h := range order {
+			if len(byContent[h]) > 1 {
+				identical = append(identical, FolderGroup{Folders: byContent[h], Overlap: 1})
+			}
+		}
+	}
+	// outermost folders first, so their subfolders are covered
+	shallowest := func(g FolderGroup) int {
+		d := g.Folders[0].depth
+		for _, f := range g.Folders {
+			d = min(d, f.depth)
+		}
+		return d
+	}
+	for _, g := range identical {
+		sortFolders(g.Folders)
+	}
+	sort.Slice(identical, func(i, j int) bool {
+		a, b := identical[i], identical[j]
+		if da, db := shallowest(a), shallowest(b); da != db {
+			return da < db
+		}
+		return a.Folders[0].path < b.Folders[0].path
+	})
+	for _, g := range identical {
+		report(g)
+	}
+	if minOverlap >= 1 {
+		return groups, nil
+	}
+
+	// near-identical: compare folders of similar size, largest first. An
+	// identical group takes part through its first folder, and whatever
+	// matches it joins that group.
+	groupOf := make(map[*folderNode]int)
+	for i, g := range groups {
+		for _, f := range g.Folders {
+			groupOf[f] = i
+		}
+	}
+	insideReported := func(n *folderNode) bool {
+		return n.parent != nil && covered(n.parent)
+	}
+	var candidates []*folderNode
+	for _, n := range nodes {
+		if n.count < minFolderFiles || n.bytes == 0 || insideReported(n) {
+			continue
+		}
+		if i, ok := groupOf[n]; ok && groups[i].Folders[0] != n {
+			continue
+		}
+		candidates = append(candidates, n)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].bytes > candidates[j].bytes })
+	joined := make(map[*folderNode]bool)
+	comparisons := 0
+	for i, a := range candidates {
+		if joined[a] {
+			continue
+		}
+		var matches []*folderNode
+		overlap := 1.0
+		for _, b := range candidates[i+1:] {
+			if float64(b.bytes) < minOverlap*float64(a.bytes) {
+				break // sorted by size: the rest are smaller still
+			}
+			if comparisons >= maxFolderComparisons {
+				break
+			}
+			if joined[b] || a.isAncestorOf(b) || b.isAncestorOf(a) {
+				continue
+			}
+			comparisons++
+			ov, err := folderOverlap(a, b, fh)
+			if err != nil {
+				return nil, err
+			}
+			if ov >= minOverlap {
+				matches = append(matches, b)
+				overlap = min(overlap, ov)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		g := FolderGroup{Folders: []*folderNode{a}, Overlap: 1}
+		if gi, ok := groupOf[a]; ok {
+			g = groups[gi]
+			groups[gi].Folders = nil // merged below
+		}
+		for _, b := range matches {
+			joined[b] = true
+			if gi, ok := groupOf[b]; ok {
+				g.Folders = append(g.Folders, groups[gi].Folders...)
+				g.Overlap = min(g.Overlap, groups[gi].Overlap)
+				groups[gi].Folders = nil
+			} else {
+				g.Folders = append(g.Folders, b)
+			}
+		}
+		g.Overlap = min(g.Overlap, overlap)
+		for _, f := range g.Folders {
+			reported[f] = true
+		}
+		groups = append(groups, g)
+		for _, f := range g.Folders {
+			groupOf[f] = len(groups) - 1
+		}
+	}
+
+	// drop merged groups and groups that now lie wholly inside others
+	var res []FolderGroup
+	for _, g := range groups {
+		if len(g.Folders) == 0 {
+			continue
+		}
+		inside := true
+		for _, f := range g.Folders {
+			if !insideReported(f) {
+				inside = false
+				break
+			}
+		}
+		if !inside {
+			sortFolders(g.Folders)
+			res = append(res, g)
+		}
+	}
+	return res, nil
+}
+
+func sortFolders(ns []*folderNode) {
+	sort.Slice(ns, func(i, j int) bool { return ns[i].path < ns[j].path })
+}
+
+// ---------------------------------------------------------------------
+//  3) Deleting and Linking
+// ---------------------------------------------------------------------
+
+// removePath deletes a file, or a folder with everything in it.
+func removePath(path string) error {
+	st, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if st.IsDir() {
+		return os.RemoveAll(path)
+	}
+	return os.Remove(path)
+}
+
+// treeSize returns the size of a file, or the total size of a folder.
+func treeSize(path string) int64 {
+	var total int64
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// withoutPartialFolders leaves out folders holding files that were not
+// compared with the other copies: members of near-identical groups, which
+// hold files the others lack, and folders with entries the scan's filters
+// left out. Deleting them would lose those files; linking, which only
+// replaces files that are the same, is offered instead.
+func (s *FileScanner) withoutPartialFolders(paths []string) (res []string, ok bool) {
+	partial := make(map[string]bool)
+	s.mu.Lock()
+	for key, group := range s.allDuplicates {
+		if strings.HasPrefix(key, nearFolderGroupPrefix) {
+			for _, g := range group {
+				partial[g] = true
+			}
+		}
+	}
+	for _, fi := range s.allFileItems {
+		if fi.partial {
+			partial[fi.filePath] = true
+		}
+	}
+	s.mu.Unlock()
+	for _, p := range paths {
+		if !partial[p] {
+			res = append(res, p)
+		}
+	}
+	if skipped := len(paths) - len(res); skipped > 0 {
+		dialog.ShowInformation("Partly Compared Folders", fmt.Sprintf(
+			"%d selected folder(s) hold files that were not compared with the other copies and were left out.\nThey either only partly match their group or hold files the scan's filters left out.\nUse Link Selected to store the files they share only once.", skipped), s.mainWindow)
+	}
+	return res, len(res) > 0
+}
+
+// linkFile replaces path with a hard link to target.
+func linkFile(target, path string) error {
+	tmp := path + ".link-tmp"
+	if err := os.Link(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// linkDuplicate replaces path (a file or folder) with hard links to kept.
+// Only files with the same relative path and content are linked; anything
+// else in a folder is left alone. Returns the files linked and bytes freed.
+func (s *FileScanner) linkDuplicate(kept, path string) (int, int64, []string) {
+	pairs := map[string]string{path: kept}
+	if st, err := os.Stat(path); err == nil && st.IsDir() {
+		pairs = make(map[string]string)
+		filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && d.Type().IsRegular() {
+				rel, _ := filepath.Rel(path, p)
+				pairs[p] = filepath.Join(kept, rel)
+			}
+			return nil
+		})
+	}
+
+	var linked int
+	var freed int64
+	var errs []string
+	for p, target := range pairs {
+		sp, err1 := os.Stat(p)
+		st, err2 := os.Stat(target)
+		if err1 != nil || err2 != nil || sp.Size() != st.Size() || os.SameFile(sp, st) {
+			continue
+		}
+		hp, err1 := s.generateHash(p, algoSHA256)
+		ht, err2 := s.generateHash(target, algoSHA256)
+		if err1 != nil || err2 != nil || hp != ht {
+			continue
+		}
+		if err := linkFile(target, p); err != nil {
+			errs = append(errs, fmt.Sprintf("Failed to link %s: %v", p, err))
+			continue
+		}
+		linked++
+		freed += sp.Size()
+	}
+	return linked, freed, errs
+}
+
+// keptCopies maps each path to a member of its group that is not in paths
+// and not inside an archive. Paths whose whole group is selected, or whose
+// group is not an exact duplicate, are left out.
+func (s *FileScanner) keptCopies(paths []string) map[string]string {
+	selected := make(map[string]bool)
+	for _, p := range paths {
+		selected[p] = true
+	}
+	kept := make(map[string]string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	archived := make(map[string]bool)
+	for _, fi := range s.allFileItems {
+		archived[fi.filePath] = fi.archive != ""
+	}
+	for key, group := range s.allDuplicates {
+		if strings.HasPrefix(key, similarGroupPrefix) || strings.HasPrefix(key, mediaGroupPrefix) {
+			continue
+		}
+		// prefer a copy in a reference folder
+		var keep string
+		for _, g := range group {
+			if selected[g] || archived[g] {
+				continue
+			}
+			if r, _ := rootOf(s.dfRoots, g); r.Reference {
+				keep = g
+				break
+			}
+			if keep == "" {
+				keep = g
+			}
+		}
+		if keep == "" {
+			continue
+		}
+		for _, g := range group {
+			if selected[g] {
+				kept[g] = keep
+			}
+		}
+	}
+	return kept
+}
+
+// linkSelected replaces the selected duplicates with hard links to a copy
+// that is kept, so the data is stored once but every path still works.
+func (s *FileScanner) linkSelected(paths []string) {
+	kept := s.keptCopies(paths)
+	var linkable []string
+	for _, p := range paths {
+		if kept[p] != "" {
+			linkable = append(linkable, p)
+		}
+	}
+	if len(linkable) == 0 {
+		dialog.ShowInformation("Nothing To Link", "Linking needs exact duplicates (files or folders) with at least one copy of each group left unselected.", s.mainWindow)
+		return
+	}
+	s.confirmGuarded("Confirm Linking", "Link", linkable, func(allowed []string) {
+		var linked int
+		var freed int64
+		var errs []string
+		var done []string // fully linked, so no longer duplicates
+		for _, p := range allowed {
+			n, b, e := s.linkDuplicate(kept[p], p)
+			linked += n
+			freed += b
+			errs = append(errs, e...)
+			if n > 0 {
+				s.addDeletionRecord(p, "Duplicate Finder (hard-linked to "+kept[p]+")")
+				if len(e) == 0 {
+					done = append(done, p)
+				}
+			}
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(errors.New(strings.Join(errs, "\n")), s.mainWindow)
+		}
+		dialog.ShowInformation("Linking Complete", fmt.Sprintf("Linked %d file(s), freeing %s.", linked, formatSize(freed)), s.mainWindow)
+		s.refreshDeletionTable()
+		s.dropFileItems(done)
+		s.refreshDuplicates()
+	})
+}
+
+// ---------------------------------------------------------------------
+//  4) Duplicate Folders Scan
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) showScanningFolders(roots []ScanRoot, filter FileFilter, minOverlap float64) {
+	pb := widget.NewProgressBarInfinite()
+	lbl := widget.NewLabel("Scanning for duplicate folders...")
+	vbox := container.NewVBox(lbl, pb)
+
+	dlg := dialog.NewCustom("Please Wait", "Cancel", vbox, s.mainWindow)
+	scan := s.startScan(dlg)
+	algo := s.settings.HashAlgorithm
+
+	go func() {
+		fh := &fileHasher{
+			s:         s,
+			algo:      algo,
+			cache:     make(map[string]string),
+			cancelled: func() bool { return !s.scanRunning(scan) },
+			progress: func(n int) {
+				text := fmt.Sprintf("Comparing folders... %d file(s) hashed", n)
+				s.postUI(func() { lbl.SetText(text) })
+			},
+		}
+		var nodes []*folderNode
+		var skipped []SkippedPath
+		var e error
+		for _, dir := range outermostRoots(roots) {
+			var tree []*folderNode
+			var sk []SkippedPath
+			if tree, sk, e = buildFolderTree(dir, filter, s.walkOptions()); e != nil {
+				break
+			}
+			nodes = append(nodes, tree...)
+			skipped = append(skipped, sk...)
+		}
+		var groups []FolderGroup
+		if e == nil {
+			groups, e = findDuplicateFolders(nodes, minOverlap, fh)
+		}
+		if e != nil {
+			s.postUI(func() {
+				if !s.finishScan(scan) {
+					return
+				}
+				dlg.Hide()
+				dialog.ShowError(e, s.mainWindow)
+			})
+			return
+		}
+
+		m := make(map[string][]string)
+		var items []*FileItem
+		for i, g := range groups {
+			key := fmt.Sprintf("%s%d", folderGroupPrefix, i+1)
+			match := "identical"
+			if g.Overlap < 1 {
+				key = fmt.Sprintf("%s%d", nearFolderGroupPrefix, i+1)
+				match = fmt.Sprintf("%.0f%% overlap", g.Overlap*100)
+			}
+			for _, n := range g.Folders {
+				note := fmt.Sprintf("folder group %d, %s, %d file(s)", i+1, match, n.count)
+				if n.partial {
+					note += ", some files filtered out"
+				}
+				m[key] = append(m[key], n.path)
+				items = append(items, &FileItem{
+					filePath: n.path,
+					size:     treeSize(n.path), // what checkUnchanged measures
+					note:     note,
+					partial:  n.partial,
+				})
+			}
+		}
+		items, m = applyRoles(roots, items, m)
+		stampModTimes(items)
+		s.saveHashCache()
+
+		s.postUI(func() {
+			if !s.finishScan(scan) {
+				return
+			}
+			s.mu.Lock()
+			s.allFileItems = items
+			s.allDuplicates = m
+			s.dfAlgorithm, s.dfVerified = algo, false
+			s.mu.Unlock()
+			dlg.Hide()
+
+			if len(items) == 0 {
+				s.showScanResult("No Duplicate Folders", "No duplicate folders found.", skipped)
+			} else {
+				msg := fmt.Sprintf("Found %d folder(s) in %d group(s).", len(items), len(m))
+				s.showScanResult("Scan Complete", msg, skipped)
+			}
+			s.refreshDuplicates()
+			s.watchDuplicates(s.dfWatchCheck.Checked)
+		})
+	}()
+}
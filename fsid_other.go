@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystemID identifies the file system holding a folder by the volume
+// of its resolved path; folders only reach other volumes through links.
+func fileSystemID(path string, info os.FileInfo) string {
+	return strings.ToLower(filepath.VolumeName(path))
+}
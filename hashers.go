@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/cespare/xxhash/v2"
+	"lukechampine.com/blake3"
+)
+
+const hashCacheFilePath = "hash_cache.json" // File hashes by algorithm, reused while size and modification time match
+
+// Content hash algorithms
+const (
+	algoSHA256 = "SHA-256"
+	algoBLAKE3 = "BLAKE3"
+	algoXXH64  = "xxHash64"
+)
+
+const defaultHashAlgorithm = algoXXH64
+
+// hashAlgorithm is a content hash the Duplicate Finder can use. Fast
+// non-cryptographic hashes can collide, so their groups can be re-checked
+// with SHA-256 (Settings.VerifyHashes).
+type hashAlgorithm struct {
+	name    string
+	newHash func() hash.Hash
+	secure  bool // collisions are practically impossible
+}
+
+var hashAlgorithms = []hashAlgorithm{
+	{algoXXH64, func() hash.Hash { return xxhash.New() }, false},
+	{algoBLAKE3, func() hash.Hash { return blake3.New(32, nil) }, true},
+	{algoSHA256, sha256.New, true},
+}
+
+func hashAlgorithmNames() []string {
+	var names []string
+	for _, a := range hashAlgorithms {
+		names = append(names, a.name)
+	}
+	return names
+}
+
+func lookupHashAlgorithm(name string) (hashAlgorithm, error) {
+	for _, a := range hashAlgorithms {
+		if a.name == name {
+			return a, nil
+		}
+	}
+	return hashAlgorithm{}, fmt.Errorf("unknown hash algorithm %q", name)
+}
+
+// ---------------------------------------------------------------------
+//  1) Hash Cache
+// ---------------------------------------------------------------------
+
+type hashCacheEntry struct {
+	Size    int64
+	ModTime time.Time
+	Hashes  map[string]string // algorithm -> hex hash, perceptual image hashes included
+	used    bool
+}
+
+// HashCache remembers file hashes between runs. A hash is only reused
+// while the file's size and modification time are unchanged, and only for
+// the algorithm it was computed with.
+type HashCache struct {
+	mu      sync.Mutex
+	entries map[string]*hashCacheEntry
+	dirty   bool
+}
+
+func loadHashCache() *HashCache {
+	hc := &HashCache{entries: make(map[string]*hashCacheEntry)}
+	data, err := os.ReadFile(hashCacheFilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Println("Error loading hash cache:", err)
+		}
+		return hc
+	}
+	if err := json.Unmarshal(data, &hc.entries); err != nil {
+		fmt.Println("Error loading hash cache:", err)
+		hc.entries = make(map[string]*hashCacheEntry)
+	}
+	return hc
+}
+
+func (hc *HashCache) lookup(path string, st os.FileInfo, algo string) (string, bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	e := hc.entries[path]
+	if e == nil || e.Size != st.Size() || !e.ModTime.Equal(st.ModTime()) {
+		return "", false
+	}
+	e.used = true
+	h, ok := e.Hashes[algo]
+	return h, ok
+}
+
+func (hc *HashCache) store(path string, st os.FileInfo, algo, sum string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	e := hc.entries[path]
+	if e == nil || e.Size != st.Size() || !e.ModTime.Equal(st.ModTime()) {
+		e = &hashCacheEntry{Size: st.Size(), ModTime: st.ModTime(), Hashes: map[string]string{}}
+		hc.entries[path] = e
+	}
+	e.used = true
+	e.Hashes[algo] = sum
+	hc.dirty = true
+}
+
+// save writes the cache, dropping files that no longer exist.
+func (hc *HashCache) save() error {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if !hc.dirty {
+		return nil
+	}
+	for p, e := range hc.entries {
+		if e.used {
+			continue
+		}
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			delete(hc.entries, p)
+		}
+	}
+	data, err := json.Marshal(hc.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(hashCacheFilePath, data, 0644); err != nil {
+		return err
+	}
+	hc.dirty = false
+	return nil
+}
+
+func (s *FileScanner) hashCache() *HashCache {
+	s.hashCacheOnce.Do(func() { s.hashes = loadHashCache() })
+	return s.hashes
+}
+
+func (s *FileScanner) saveHashCache() {
+	if err := s.hashCache().save(); err != nil {
+		fmt.Println("Error saving hash cache:", err)
+	}
+}
+
+// ---------------------------------------------------------------------
+//  2) Verification
+// ---------------------------------------------------------------------
+
+// verifyGroups splits groups found with a fast hash by their SHA-256, so a
+// collision cannot pass as a duplicate. known holds SHA-256 hashes that
+// cannot be read from disk, such as those of files inside archives.
+func (s *FileScanner) verifyGroups(groups map[string][]string, known map[string]string) map[string][]string {
+	res := make(map[string][]string)
+	for key, group := range groups {
+		size := key[strings.LastIndex(key, "-"):] // keys are hash-size
+		for _, p := range group {
+			sum, ok := known[p]
+			if !ok {
+				var err error
+				if sum, err = s.generateHash(p, algoSHA256); err != nil {
+					continue
+				}
+			}
+			res[sum+size] = append(res[sum+size], p)
+		}
+	}
+	return duplicateGroups(res)
+}
+
+// ---------------------------------------------------------------------
+//  3) Exporting Results
+// ---------------------------------------------------------------------
+
+type ExportFile struct {
+	Path     string
+	Size     int64
+	Note     string `json:",omitempty"`
+	Selected bool
+}
+
+type ExportGroup struct {
+	Key   string
+	Files []ExportFile
+}
+
+// DuplicateExport is the Duplicate Finder's results as written by Export
+// Results. Algorithm names how the groups were found, so exports from
+// different runs can be compared.
+type DuplicateExport struct {
+	Created   string
+	Algorithm string
+	Verified  bool // fast-hash groups were re-checked with SHA-256
+	Groups    []ExportGroup
+}
+
+func (s *FileScanner) duplicateExport() DuplicateExport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byPath := make(map[string]*FileItem)
+	for _, fi := range s.allFileItems {
+		byPath[fi.filePath] = fi
+	}
+	exp := DuplicateExport{
+		Created:   time.Now().Format("2006-01-02 15:04:05"),
+		Algorithm: s.dfAlgorithm,
+		Verified:  s.dfVerified,
+	}
+	for key, group := range s.allDuplicates {
+		g := ExportGroup{Key: key}
+		for _, p := range group {
+			if fi := byPath[p]; fi != nil {
+				g.Files = append(g.Files, ExportFile{Path: p, Size: fi.size, Note: fi.note, Selected: fi.selected})
+			}
+		}
+		if len(g.Files) > 0 {
+			exp.Groups = append(exp.Groups, g)
+		}
+	}
+	sort.Slice(exp.Groups, func(i, j int) bool { return exp.Groups[i].Files[0].Path < exp.Groups[j].Files[0].Path })
+	return exp
+}
+
+func (s *FileScanner) exportDuplicates() {
+	exp := s.duplicateExport()
+	if len(exp.Groups) == 0 {
+		dialog.ShowInformation("Nothing To Export", "Run a scan first.", s.mainWindow)
+		return
+	}
+	dialog.ShowFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+		data, err := json.MarshalIndent(exp, "", "  ")
+		if err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return
+		}
+		if _, err := w.Write(data); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return
+		}
+		dialog.ShowInformation("Exported", fmt.Sprintf("Exported %d group(s).", len(exp.Groups)), s.mainWindow)
+	}, s.mainWindow)
+}
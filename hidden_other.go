@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// isHiddenFile treats names starting with a dot as hidden.
+func isHiddenFile(path string, info os.FileInfo) bool {
+	return strings.HasPrefix(info.Name(), ".") && info.Name() != "." && info.Name() != ".."
+}
+
+// isSystemFile is always false, as this platform has no system attribute.
+func isSystemFile(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// isHiddenFile reports whether the file has the hidden attribute.
+func isHiddenFile(path string, info os.FileInfo) bool {
+	if d, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return d.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+	}
+	return false
+}
+
+// isSystemFile reports whether the file has the system attribute.
+func isSystemFile(info os.FileInfo) bool {
+	if d, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return d.FileAttributes&syscall.FILE_ATTRIBUTE_SYSTEM != 0
+	}
+	return false
+}
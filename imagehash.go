@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// Perceptual hash algorithms
+const (
+	hashAverage    = "aHash" // brighter or darker than the mean, 8x8
+	hashDifference = "dHash" // brighter than the right-hand neighbour, 9x8
+	hashPerceptual = "pHash" // low frequencies of a 32x32 DCT
+)
+
+var imageHashNames = []string{hashDifference, hashAverage, hashPerceptual}
+
+const (
+	defaultImageHash    = hashDifference
+	defaultMaxDistance  = 10         // Hamming distance (of 64 bits) for "similar"
+	maxImageDistance    = 32         // Upper end of the threshold slider
+	similarGroupPrefix  = "similar:" // allDuplicates keys of similar-image groups
+	imageThumbnailSize  = 160        // Preview size in pixels
+	imageProgressUpdate = 25         // Update the progress label every n images
+)
+
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tif", ".tiff", ".webp"}
+
+// ---------------------------------------------------------------------
+//  1) Perceptual Hashes
+// ---------------------------------------------------------------------
+
+func isImageFile(path string) bool {
+	return contains(imageExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+func decodeImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// grayPixels scales img to w×h and returns its luminance row by row.
+func grayPixels(img image.Image, w, h int) []float64 {
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	px := make([]float64, w*h)
+	for i, v := range dst.Pix {
+		px[i] = float64(v)
+	}
+	return px
+}
+
+// imageHash returns the 64-bit perceptual hash of img. Images that look
+// alike have hashes that differ in few bits.
+func imageHash(img image.Image, algo string) uint64 {
+	var h uint64
+	switch algo {
+	case hashAverage:
+		px := grayPixels(img, 8, 8)
+		var mean float64
+		for _, v := range px {
+			mean += v
+		}
+		mean /= float64(len(px))
+		for i, v := range px {
+			if v > mean {
+				h |= 1 << uint(i)
+			}
+		}
+	case hashPerceptual:
+		coeffs := dctLowFrequencies(grayPixels(img, 32, 32), 32, 8)
+		// the median ignores the DC term, which is only the mean brightness
+		sorted := append([]float64(nil), coeffs[1:]...)
+		sort.Float64s(sorted)
+		median := sorted[len(sorted)/2]
+		for i, v := range coeffs {
+			if v > median {
+				h |= 1 << uint(i)
+			}
+		}
+	default: // hashDifference
+		px := grayPixels(img, 9, 8)
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				if px[y*9+x] > px[y*9+x+1] {
+					h |= 1 << uint(y*8+x)
+				}
+			}
+		}
+	}
+	return h
+}
+
+// dctLowFrequencies runs a 2D DCT-II over an n×n block and returns the top
+// left k×k coefficients row by row.
+func dctLowFrequencies(px []float64, n, k int) []float64 {
+	cos := make([]float64, k*n)
+	for u := 0; u < k; u++ {
+		for x := 0; x < n; x++ {
+			cos[u*n+x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / float64(2*n))
+		}
+	}
+	// rows first, then columns
+	rows := make([]float64, n*k)
+	for y := 0; y < n; y++ {
+		for u := 0; u < k; u++ {
+			var sum float64
+			for x := 0; x < n; x++ {
+				sum += px[y*n+x] * cos[u*n+x]
+			}
+			rows[y*k+u] = sum
+		}
+	}
+	out := make([]float64, k*k)
+	for v := 0; v < k; v++ {
+		for u := 0; u < k; u++ {
+			var sum float64
+			for y := 0; y < n; y++ {
+				sum += rows[y*k+u] * cos[v*n+y]
+			}
+			out[v*k+u] = sum
+		}
+	}
+	return out
+}
+
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// bkTree indexes 64-bit hashes by Hamming distance, so the hashes near a
+// given one are found without comparing against all of them.
+type bkTree struct {
+	root *bkNode
+}
+
+type bkNode struct {
+	hash     uint64
+	items    []int // indexes of the paths with exactly this hash
+	children map[int]*bkNode
+}
+
+func (t *bkTree) add(hash uint64, item int) {
+	if t.root == nil {
+		t.root = &bkNode{hash: hash, items: []int{item}}
+		return
+	}
+	n := t.root
+	for {
+		d := hammingDistance(hash, n.hash)
+		if d == 0 {
+			n.items = append(n.items, item)
+			return
+		}
+		child := n.children[d]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[int]*bkNode)
+			}
+			n.children[d] = &bkNode{hash: hash, items: []int{item}}
+			return
+		}
+		n = child
+	}
+}
+
+// within calls fn for every item whose hash is at most maxDist from hash.
+func (t *bkTree) within(hash uint64, maxDist int, fn func(item int)) {
+	if t.root == nil {
+		return
+	}
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := hammingDistance(hash, n.hash)
+		if d <= maxDist {
+			for _, it := range n.items {
+				fn(it)
+			}
+		}
+		for cd, child := range n.children {
+			if cd >= d-maxDist && cd <= d+maxDist {
+				stack = append(stack, child)
+			}
+		}
+	}
+}
+
+// clusterSimilar groups paths whose hashes are within maxDist of a group's
+// centre, the first image of the group. Images are taken in path order; an
+// image not yet in a group becomes a centre and collects the ungrouped
+// images near it. Groups are not linked transitively, so a chain of
+// slightly different images does not end up as one group. Only groups of
+// two or more are returned.
+func clusterSimilar(hashes map[string]uint64, maxDist int) [][]string {
+	paths := make([]string, 0, len(hashes))
+	for p := range hashes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var tree bkTree
+	for i, p := range paths {
+		tree.add(hashes[p], i)
+	}
+
+	grouped := make([]bool, len(paths))
+	var groups [][]string
+	for i, centre := range paths {
+		if grouped[i] {
+			continue
+		}
+		grouped[i] = true
+		var near []int
+		tree.within(hashes[centre], maxDist, func(j int) {
+			if !grouped[j] {
+				near = append(near, j)
+			}
+		})
+		if len(near) == 0 {
+			continue
+		}
+		sort.Ints(near)
+		group := []string{centre}
+		for _, j := range near {
+			grouped[j] = true
+			group = append(group, paths[j])
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// cachedImageHash returns the perceptual hash of the image at path, taking
+// it from the hash cache while the file is unchanged.
+func (s *FileScanner) cachedImageHash(path, algo string) (uint64, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if sum, ok := s.hashCache().lookup(path, st, algo); ok {
+		if h, err := strconv.ParseUint(sum, 16, 64); err == nil {
+			return h, nil
+		}
+	}
+	img, err := decodeImage(path)
+	if err != nil {
+		return 0, err
+	}
+	h := imageHash(img, algo)
+	s.hashCache().store(path, st, algo, fmt.Sprintf("%016x", h))
+	return h, nil
+}
+
+// thumbnail decodes the image at path and scales it to fit size×size.
+func thumbnail(path string, size int) (image.Image, image.Point, error) {
+	img, err := decodeImage(path)
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+	b := img.Bounds()
+	w, h := size, size
+	if b.Dx() > b.Dy() {
+		h = max(1, size*b.Dy()/b.Dx())
+	} else {
+		w = max(1, size*b.Dx()/b.Dy())
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst, b.Size(), nil
+}
+
+// ---------------------------------------------------------------------
+//  2) Similar Images Scan
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) showScanningSimilarImages(roots []ScanRoot, filter FileFilter, algo string, maxDist int) {
+	pb := widget.NewProgressBarInfinite()
+	lbl := widget.NewLabel("Scanning for similar images...")
+	vbox := container.NewVBox(lbl, pb)
+
+	dlg := dialog.NewCustom("Please Wait", "Cancel", vbox, s.mainWindow)
+	scan := s.startScan(dlg)
+
+	go func() {
+		files, skipped, e := s.scanRoots(roots, filter)
+		if e != nil {
+			s.postUI(func() {
+				if !s.finishScan(scan) {
+					return
+				}
+				dlg.Hide()
+				dialog.ShowError(e, s.mainWindow)
+			})
+			return
+		}
+		var images []string
+		for _, f := range files {
+			if isImageFile(f) {
+				images = append(images, f)
+			}
+		}
+
+		hashes := make(map[string]uint64)
+		for i, f := range images {
+			if !s.scanRunning(scan) {
+				return
+			}
+			if i%imageProgressUpdate == 0 {
+				text := fmt.Sprintf("Hashing images... %d of %d", i, len(images))
+				s.postUI(func() { lbl.SetText(text) })
+			}
+			h, err := s.cachedImageHash(f, algo)
+			if err != nil {
+				continue // not decodable
+			}
+			hashes[f] = h
+		}
+		s.saveHashCache()
+
+		m := make(map[string][]string)
+		var items []*FileItem
+		for i, group := range clusterSimilar(hashes, maxDist) {
+			m[fmt.Sprintf("%s%s:%d", similarGroupPrefix, algo, i+1)] = group
+			for _, p := range group {
+				items = append(items, &FileItem{
+					filePath: p,
+					size:     fileSize(p),
+					note:     fmt.Sprintf("similar group %d, distance %d", i+1, hammingDistance(hashes[group[0]], hashes[p])),
+				})
+			}
+		}
+		items, m = applyRoles(roots, items, m)
+		stampModTimes(items)
+
+		s.postUI(func() {
+			if !s.finishScan(scan) {
+				return
+			}
+			s.mu.Lock()
+			s.allFileItems = items
+			s.allDuplicates = m
+			s.imageHashes = hashes
+			s.dfAlgorithm, s.dfVerified = algo, false
+			s.mu.Unlock()
+			dlg.Hide()
+
+			if len(items) == 0 {
+				s.showScanResult("No Similar Images", fmt.Sprintf("No similar images found among %d image(s).", len(hashes)), skipped)
+			} else {
+				msg := fmt.Sprintf("Found %d similar image(s) in %d group(s).", len(items), len(m))
+				s.showScanResult("Scan Complete", msg, skipped)
+			}
+			s.refreshDuplicates()
+			s.watchDuplicates(s.dfWatchCheck.Checked)
+		})
+	}()
+}
+
+// ---------------------------------------------------------------------
+//  3) Thumbnail Preview
+// ---------------------------------------------------------------------
+
+// showSimilarGroup shows thumbnails of every image in path's group side by
+// side in the Duplicate Finder preview.
+func (s *FileScanner) showSimilarGroup(path string) {
+	var group []string
+	s.mu.Lock()
+	for key, g := range s.allDuplicates {
+		if !strings.HasPrefix(key, similarGroupPrefix) {
+			continue
+		}
+		for _, p := range g {
+			if p == path {
+				group = append([]string(nil), g...)
+			}
+		}
+	}
+	hashes := s.imageHashes
+	s.dfPreviewPath = path
+	s.mu.Unlock()
+
+	if len(group) == 0 {
+		s.dfPreview.Objects = nil
+		s.dfPreview.Refresh()
+		return
+	}
+
+	go func() {
+		var cards []fyne.CanvasObject
+		for _, p := range group {
+			thumb, dims, err := thumbnail(p, imageThumbnailSize)
+			if err != nil {
+				continue
+			}
+			img := canvas.NewImageFromImage(thumb)
+			img.FillMode = canvas.ImageFillContain
+			img.SetMinSize(fyne.NewSize(imageThumbnailSize, imageThumbnailSize))
+			name := widget.NewLabel(filepath.Base(p))
+			name.Truncation = fyne.TextTruncateEllipsis
+			name.TextStyle.Bold = p == path
+			info := widget.NewLabel(fmt.Sprintf("%dx%d, %s\ndistance %d", dims.X, dims.Y,
+				formatSize(fileSize(p)), hammingDistance(hashes[path], hashes[p])))
+			cards = append(cards, container.NewVBox(img, name, info))
+		}
+		s.postUI(func() {
+			s.mu.Lock()
+			current := s.dfPreviewPath == path
+			s.mu.Unlock()
+			if !current {
+				return // another row was clicked meanwhile
+			}
+			s.dfPreview.Objects = cards
+			s.dfPreview.Refresh()
+		})
+	}()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// newInUseChecker returns a function reporting whether another process has a
+// file open. /proc is read once, on the first call.
+func newInUseChecker() func(path string) bool {
+	var open map[string]bool
+	return func(path string) bool {
+		if open == nil {
+			open = map[string]bool{}
+			self := strconv.Itoa(os.Getpid())
+			fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+			for _, fd := range fds {
+				if filepath.Base(filepath.Dir(filepath.Dir(fd))) == self {
+					continue
+				}
+				if target, err := os.Readlink(fd); err == nil {
+					open[target] = true
+				}
+			}
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return false
+		}
+		return open[abs]
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// newInUseChecker returns a function reporting whether another process has a
+// file open, by trying to open it without sharing.
+func newInUseChecker() func(path string) bool {
+	return func(path string) bool {
+		p, err := windows.UTF16PtrFromString(path)
+		if err != nil {
+			return false
+		}
+		h, err := windows.CreateFile(p, windows.GENERIC_READ, 0, nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+		if err != nil {
+			return err == windows.ERROR_SHARING_VIOLATION || err == windows.ERROR_LOCK_VIOLATION
+		}
+		windows.CloseHandle(h)
+		return false
+	}
+}
@@ -0,0 +1,860 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const cleanupJobsFilePath = "cleanup_jobs.json" // Saved scheduled cleanup jobs
+const jobRunsFilePath = "job_runs.log"          // Job-run log, one JSON record per line
+const jobLockFilePath = "jobs.lock"             // PID of the process running the scheduler
+const jobReportsDir = "job_reports"             // Output of "report" jobs
+const quarantineDir = "quarantine"              // Files moved aside by "quarantine" jobs
+
+const jobCheckInterval = time.Minute
+
+// ---------------------------------------------------------------------
+//  1) Cleanup Jobs
+// ---------------------------------------------------------------------
+
+const (
+	jobActionReport     = "report"     // list matching files only
+	jobActionQuarantine = "quarantine" // move them into quarantineDir
+	jobActionDelete     = "delete"
+)
+
+var jobActions = []string{jobActionReport, jobActionQuarantine, jobActionDelete}
+
+// CleanupJob is a saved Space Cleaner scan that runs on a schedule. Targets
+// accept the same path templates as cleanup profiles.
+type CleanupJob struct {
+	Name            string
+	Targets         []string
+	MinSizeMB       float64  `json:",omitempty"`
+	NotModifiedDays int      `json:",omitempty"`
+	NotAccessedDays int      `json:",omitempty"`
+	Include         []string `json:",omitempty"`
+	Exclude         []string `json:",omitempty"`
+	Category        string   `json:",omitempty"` // only files of this category
+	Action          string
+	Every           string // Go duration, e.g. "24h"
+	CatchUp         bool   // run once when runs were missed, instead of skipping them
+	AllowCaution    bool   `json:",omitempty"` // quarantine or delete inside caution targets too
+	Enabled         bool
+}
+
+// JobRun is one entry of the job-run log.
+type JobRun struct {
+	Job       string
+	Action    string
+	Started   string
+	Finished  string
+	Skipped   bool   `json:",omitempty"` // missed runs skipped without scanning
+	Note      string `json:",omitempty"`
+	Matched   int
+	Bytes     int64
+	Processed int      // files reported, quarantined or deleted
+	Protected int      // files left alone by the protection policy
+	Output    string   `json:",omitempty"` // report file or quarantine directory
+	Errors    []string `json:",omitempty"`
+}
+
+func (j CleanupJob) interval() (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(j.Every))
+	if err != nil {
+		return 0, fmt.Errorf("job %s: invalid interval %q", j.Name, j.Every)
+	}
+	if d < jobCheckInterval {
+		return 0, fmt.Errorf("job %s: interval must be at least %s", j.Name, jobCheckInterval)
+	}
+	return d, nil
+}
+
+func (j CleanupJob) filter() FileFilter {
+	return FileFilter{
+		MinSize:         int64(j.MinSizeMB * 1048576),
+		NotModifiedDays: j.NotModifiedDays,
+		NotAccessedDays: j.NotAccessedDays,
+		IncludePatterns: j.Include,
+		ExcludePatterns: j.Exclude,
+	}
+}
+
+func validateCleanupJob(j CleanupJob) error {
+	if strings.TrimSpace(j.Name) == "" {
+		return fmt.Errorf("job has no name")
+	}
+	if len(j.Targets) == 0 {
+		return fmt.Errorf("job %s has no targets", j.Name)
+	}
+	switch j.Action {
+	case jobActionReport, jobActionQuarantine, jobActionDelete:
+	default:
+		return fmt.Errorf("job %s has unknown action %q", j.Name, j.Action)
+	}
+	_, err := j.interval()
+	return err
+}
+
+func loadCleanupJobs() ([]CleanupJob, error) {
+	data, err := os.ReadFile(cleanupJobsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var jobs []CleanupJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", cleanupJobsFilePath, err)
+	}
+	return jobs, nil
+}
+
+func saveCleanupJobs(jobs []CleanupJob) error {
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cleanupJobsFilePath, data, 0644)
+}
+
+func appendJobRun(run JobRun) error {
+	f, err := os.OpenFile(jobRunsFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(run)
+}
+
+func loadJobRuns() []JobRun {
+	data, err := os.ReadFile(jobRunsFilePath)
+	if err != nil {
+		return nil
+	}
+	var runs []JobRun
+	for _, line := range strings.Split(string(data), "\n") {
+		var r JobRun
+		if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &r) != nil {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs
+}
+
+// lastJobRuns returns the start time of the latest run of every job.
+func lastJobRuns(runs []JobRun) map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, r := range runs {
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", r.Started, time.Local)
+		if err != nil {
+			continue
+		}
+		if t.After(last[r.Job]) {
+			last[r.Job] = t
+		}
+	}
+	return last
+}
+
+// jobDue reports whether a job that last ran at last should run now, and how
+// many whole intervals were missed on top of the one that is due.
+func jobDue(last, now time.Time, every time.Duration) (bool, int) {
+	if last.IsZero() {
+		return true, 0
+	}
+	next := last.Add(every)
+	if now.Before(next) {
+		return false, 0
+	}
+	return true, int(now.Sub(next) / every)
+}
+
+// ---------------------------------------------------------------------
+//  2) Running a Job
+// ---------------------------------------------------------------------
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// runCleanupJob scans the job's targets and applies its action. Files that the
+// protection policy blocks or warns about are never touched by unattended runs.
+func runCleanupJob(job CleanupJob, note string) (JobRun, []DeletionRecord) {
+	start := time.Now()
+	run := JobRun{Job: job.Name, Action: job.Action, Started: start.Format("2006-01-02 15:04:05"), Note: note}
+	filter := job.filter()
+
+	type match struct {
+		path, root string
+		size       int64
+	}
+	var matches []match
+	seen := make(map[string]bool)
+	for _, t := range job.Targets {
+		root := expandPathTemplate(t)
+		// unattended runs never follow links, whatever the settings say
+		skipped, err := walkTree(root, WalkOptions{}, func(p string, info os.FileInfo) error {
+			if info.IsDir() || seen[p] {
+				return nil
+			}
+			seen[p] = true
+			if !filter.matches(p, info, start) {
+				return nil
+			}
+			if job.Category != "" && fileCategory(p) != job.Category {
+				return nil
+			}
+			matches = append(matches, match{path: p, root: root, size: info.Size()})
+			return nil
+		})
+		if err != nil {
+			run.Errors = append(run.Errors, err.Error())
+		}
+		for _, sk := range skipped {
+			if sk.Reason != linkNotFollowed {
+				run.Errors = append(run.Errors, sk.Path+": "+sk.Reason)
+			}
+		}
+	}
+
+	pp := newProtectionPolicy()
+	var caution []string
+	if job.Action != jobActionReport && !job.AllowCaution {
+		caution = cautionDirs()
+	}
+	stamp := start.Format("20060102-150405")
+	name := unsafeNameChars.ReplaceAllString(job.Name, "_")
+	var report strings.Builder
+	var records []DeletionRecord
+	for _, m := range matches {
+		run.Matched++
+		run.Bytes += m.size
+		if v := pp.check(m.path); v.Level != protectNone {
+			run.Protected++
+			report.WriteString(fmt.Sprintf("%s\t%d\tskipped: %s\n", m.path, m.size, v.Reason))
+			continue
+		}
+		if dir, ok := underAny(caution, m.path); ok {
+			run.Protected++
+			report.WriteString(fmt.Sprintf("%s\t%d\tskipped: inside the caution target %s\n", m.path, m.size, dir))
+			continue
+		}
+
+		switch job.Action {
+		case jobActionReport:
+			report.WriteString(fmt.Sprintf("%s\t%d\n", m.path, m.size))
+			run.Processed++
+			continue
+		case jobActionQuarantine:
+			rel, err := filepath.Rel(m.root, m.path)
+			if err != nil {
+				rel = filepath.Base(m.path)
+			}
+			dest := filepath.Join(quarantineDir, name, stamp, filepath.Base(m.root), rel)
+			if err := moveFile(m.path, dest); err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("Failed to quarantine %s: %v", m.path, err))
+				continue
+			}
+			run.Output = filepath.Join(quarantineDir, name, stamp)
+		case jobActionDelete:
+			if err := os.Remove(m.path); err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("Failed to delete %s: %v", m.path, err))
+				continue
+			}
+		}
+		run.Processed++
+		records = append(records, DeletionRecord{
+			Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+			FilePath:  m.path,
+			Method:    fmt.Sprintf("Job %s (%s)", job.Name, job.Action),
+		})
+	}
+
+	if report.Len() > 0 {
+		out := filepath.Join(jobReportsDir, name+"-"+stamp+".txt")
+		err := os.MkdirAll(jobReportsDir, 0755)
+		if err == nil {
+			err = os.WriteFile(out, []byte(report.String()), 0644)
+		}
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("Failed to write report: %v", err))
+		} else if job.Action == jobActionReport {
+			run.Output = out
+		}
+	}
+	run.Finished = time.Now().Format("2006-01-02 15:04:05")
+	return run, records
+}
+
+// moveFile renames src to dst, copying across file systems when needed.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		in.Close()
+		return err
+	}
+	_, err = io.Copy(out, in)
+	in.Close()
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// ---------------------------------------------------------------------
+//  3) Scheduler
+// ---------------------------------------------------------------------
+
+// JobScheduler checks the saved jobs once a minute and runs the ones that are
+// due. Only one process (the app or the daemon) runs jobs at a time; the
+// owner holds jobLockFilePath.
+type JobScheduler struct {
+	mu      sync.Mutex
+	running map[string]bool
+	owner   bool
+	onRun   func(JobRun)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newJobScheduler() *JobScheduler {
+	return &JobScheduler{
+		running: map[string]bool{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// acquireJobLock takes the scheduler lock unless another live process holds it.
+// It returns the PID of the holder. The lock file is created exclusively, so
+// two processes starting together cannot both take it; a lock left behind by
+// a process that is gone, or whose PID now belongs to another program, is
+// taken over.
+func acquireJobLock() (bool, int32) {
+	me := os.Getpid()
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(jobLockFilePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(me))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				fmt.Println("Error writing job lock:", err)
+				os.Remove(jobLockFilePath)
+				return false, 0
+			}
+			return true, int32(me)
+		}
+		if !os.IsExist(err) {
+			fmt.Println("Error creating job lock:", err)
+			return false, 0
+		}
+
+		data, err := os.ReadFile(jobLockFilePath)
+		if err != nil {
+			continue // released in the meantime
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			// the holder may not have written its PID yet
+			if st, err := os.Stat(jobLockFilePath); err == nil && time.Since(st.ModTime()) < 10*time.Second {
+				return false, 0
+			}
+		} else if pid == me {
+			return true, int32(me)
+		} else if isJobScheduler(int32(pid)) {
+			return false, int32(pid)
+		}
+		if !removeStaleJobLock(data) {
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// isJobScheduler reports whether pid is a live process running this program.
+func isJobScheduler(pid int32) bool {
+	if alive, _ := process.PidExists(pid); !alive {
+		return false
+	}
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return false
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return true
+	}
+	if exe, err := p.Exe(); err == nil {
+		return normalizePath(filepath.Base(exe)) == normalizePath(filepath.Base(self))
+	}
+	if name, err := p.Name(); err == nil {
+		return normalizePath(name) == normalizePath(filepath.Base(self))
+	}
+	return true // cannot tell; assume the lock is held
+}
+
+// removeStaleJobLock moves the lock aside if it still holds stale, so that
+// two processes taking over the same stale lock cannot remove each other's
+// new one. It reports whether the lock is gone.
+func removeStaleJobLock(stale []byte) bool {
+	aside := fmt.Sprintf("%s.%d", jobLockFilePath, os.Getpid())
+	if err := os.Rename(jobLockFilePath, aside); err != nil {
+		return os.IsNotExist(err)
+	}
+	data, err := os.ReadFile(aside)
+	if err == nil && string(data) != string(stale) {
+		// another process took the lock over first; put it back
+		os.Rename(aside, jobLockFilePath)
+		return false
+	}
+	os.Remove(aside)
+	return true
+}
+
+// ownerStatus reports whether this process runs the scheduled jobs, and otherwise
+// the PID of the process that does.
+func (js *JobScheduler) ownerStatus() (bool, int32) {
+	if data, err := os.ReadFile(jobLockFilePath); err == nil {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return pid == os.Getpid(), int32(pid)
+		}
+	}
+	return false, 0
+}
+
+// claim takes the scheduler lock if no other process holds it, as check
+// does, and otherwise returns the PID of the holder if known.
+func (js *JobScheduler) claim() (bool, int32) {
+	owner, pid := acquireJobLock()
+	js.mu.Lock()
+	js.owner = owner
+	js.mu.Unlock()
+	return owner, pid
+}
+
+func (js *JobScheduler) start() {
+	go func() {
+		defer close(js.done)
+		ticker := time.NewTicker(jobCheckInterval)
+		defer ticker.Stop()
+		js.check()
+		for {
+			select {
+			case <-js.stop:
+				return
+			case <-ticker.C:
+				js.check()
+			}
+		}
+	}()
+}
+
+// shutdown stops the scheduler and releases the lock. A job that is running
+// finishes first.
+func (js *JobScheduler) shutdown() {
+	close(js.stop)
+	<-js.done
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if js.owner {
+		os.Remove(jobLockFilePath)
+	}
+}
+
+// check runs every enabled job that is due. Jobs are re-read from disk each
+// time so edits made in the app reach a running daemon.
+func (js *JobScheduler) check() {
+	if owner, _ := js.claim(); !owner {
+		return
+	}
+
+	jobs, err := loadCleanupJobs()
+	if err != nil {
+		fmt.Println("Error loading cleanup jobs:", err)
+		return
+	}
+	last := lastJobRuns(loadJobRuns())
+	now := time.Now()
+	for _, job := range jobs {
+		if !job.Enabled {
+			continue
+		}
+		every, err := job.interval()
+		if err != nil {
+			fmt.Println("Error in cleanup job:", err)
+			continue
+		}
+		due, missed := jobDue(last[job.Name], now, every)
+		if !due {
+			continue
+		}
+		if missed > 0 && !job.CatchUp {
+			js.record(JobRun{
+				Job:      job.Name,
+				Action:   job.Action,
+				Started:  now.Format("2006-01-02 15:04:05"),
+				Finished: now.Format("2006-01-02 15:04:05"),
+				Skipped:  true,
+				Note:     fmt.Sprintf("missed %d run(s) while not running; skipped", missed+1),
+			}, nil)
+			continue
+		}
+		note := ""
+		if missed > 0 {
+			note = fmt.Sprintf("catching up %d missed run(s)", missed+1)
+		}
+		js.runJob(job, note)
+	}
+}
+
+// runJob runs job unless it is already running. It blocks until the run ends.
+func (js *JobScheduler) runJob(job CleanupJob, note string) bool {
+	js.mu.Lock()
+	if js.running[job.Name] {
+		js.mu.Unlock()
+		return false
+	}
+	js.running[job.Name] = true
+	js.mu.Unlock()
+
+	run, records := runCleanupJob(job, note)
+
+	js.mu.Lock()
+	delete(js.running, job.Name)
+	js.mu.Unlock()
+	js.record(run, records)
+	return true
+}
+
+func (js *JobScheduler) record(run JobRun, records []DeletionRecord) {
+	if len(records) > 0 {
+		if err := appendDeletionHistory(records...); err != nil {
+			fmt.Println("Error writing deletion history:", err)
+		}
+	}
+	if err := appendJobRun(run); err != nil {
+		fmt.Println("Error writing job-run log:", err)
+	}
+	js.mu.Lock()
+	onRun := js.onRun
+	js.mu.Unlock()
+	if onRun != nil {
+		onRun(run)
+	}
+}
+
+// runJobDaemon runs the scheduler without a window until interrupted.
+func runJobDaemon() {
+	js := newJobScheduler()
+	js.onRun = func(r JobRun) {
+		fmt.Printf("%s  %s\n", r.Started, describeJobRun(r))
+	}
+	if ok, pid := acquireJobLock(); !ok {
+		fmt.Printf("Scheduled jobs are already run by process %d.\n", pid)
+		os.Exit(1)
+	}
+	fmt.Println("Running scheduled cleanup jobs. Press Ctrl+C to stop.")
+	js.start()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	js.shutdown()
+}
+
+func describeJobRun(r JobRun) string {
+	if r.Skipped {
+		return fmt.Sprintf("%s: %s", r.Job, r.Note)
+	}
+	var verb string
+	switch r.Action {
+	case jobActionReport:
+		verb = "reported"
+	case jobActionQuarantine:
+		verb = "quarantined"
+	default:
+		verb = "deleted"
+	}
+	text := fmt.Sprintf("%s: %d file(s) matched (%s), %d %s, %d protected",
+		r.Job, r.Matched, formatSize(r.Bytes), r.Processed, verb, r.Protected)
+	if len(r.Errors) > 0 {
+		text += fmt.Sprintf(", %d error(s)", len(r.Errors))
+	}
+	if r.Note != "" {
+		text += " — " + r.Note
+	}
+	if r.Output != "" {
+		text += " — " + r.Output
+	}
+	return text
+}
+
+// ---------------------------------------------------------------------
+//  4) Scheduled Jobs UI
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) setupJobsUI() fyne.CanvasObject {
+	jobs, err := loadCleanupJobs()
+	if err != nil {
+		fmt.Println("Error loading cleanup jobs:", err)
+	}
+	selectedJob := -1
+
+	describe := func(j CleanupJob) string {
+		state := "on"
+		if !j.Enabled {
+			state = "off"
+		}
+		missed := "skip missed runs"
+		if j.CatchUp {
+			missed = "catch up missed runs"
+		}
+		return fmt.Sprintf("[%s] %s — %s every %s, %s — %s", state, j.Name, j.Action, j.Every, missed, strings.Join(j.Targets, ", "))
+	}
+
+	jobList := widget.NewList(
+		func() int { return len(jobs) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(describe(jobs[id]))
+		},
+	)
+	jobList.OnSelected = func(id widget.ListItemID) { selectedJob = id }
+	jobList.OnUnselected = func(widget.ListItemID) { selectedJob = -1 }
+
+	saveJobs := func() {
+		if err := saveCleanupJobs(jobs); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+		}
+		jobList.Refresh()
+	}
+
+	addBtn := s.commandButton(toolJobs, "Add Job", nil, func() {
+		nameEntry := widget.NewEntry()
+		targetsEntry := widget.NewMultiLineEntry()
+		targetsEntry.SetPlaceHolder("One directory per line, e.g. %TEMP% or ~/Downloads")
+		minSizeEntry := widget.NewEntry()
+		minSizeEntry.SetText(strconv.Itoa(defaultMinSizeMB))
+		modifiedEntry := widget.NewEntry()
+		modifiedEntry.SetPlaceHolder("any")
+		accessedEntry := widget.NewEntry()
+		accessedEntry.SetPlaceHolder("any")
+		includeEntry := widget.NewEntry()
+		includeEntry.SetPlaceHolder("e.g. *.iso, *.zip")
+		excludeEntry := widget.NewEntry()
+		excludeEntry.SetPlaceHolder("e.g. */node_modules/*")
+		categorySelect := widget.NewSelect(append([]string{"All"}, fileCategories...), nil)
+		categorySelect.SetSelected("All")
+		actionSelect := widget.NewSelect(jobActions, nil)
+		actionSelect.SetSelected(jobActionReport)
+		everyEntry := widget.NewEntry()
+		everyEntry.SetText("24h")
+		catchUpCheck := widget.NewCheck("Run once if runs were missed", nil)
+		cautionCheck := widget.NewCheck("Also quarantine or delete inside caution targets", nil)
+
+		dialog.ShowForm("Add Cleanup Job", "Save", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Name", nameEntry),
+			widget.NewFormItem("Targets", container.NewGridWrap(fyne.NewSize(400, 80), targetsEntry)),
+			widget.NewFormItem("Minimum size (MB)", minSizeEntry),
+			widget.NewFormItem("Not modified in (days)", modifiedEntry),
+			widget.NewFormItem("Not accessed in (days)", accessedEntry),
+			widget.NewFormItem("Include", includeEntry),
+			widget.NewFormItem("Exclude", excludeEntry),
+			widget.NewFormItem("Category", categorySelect),
+			widget.NewFormItem("Action", actionSelect),
+			widget.NewFormItem("Every", everyEntry),
+			widget.NewFormItem("Missed runs", catchUpCheck),
+			widget.NewFormItem("Caution targets", cautionCheck),
+		}, func(confirm bool) {
+			if !confirm {
+				return
+			}
+			f, err := parseFileFilter(minSizeEntry.Text, modifiedEntry.Text, accessedEntry.Text, includeEntry.Text, excludeEntry.Text)
+			if err != nil {
+				dialog.ShowInformation("Invalid Input", err.Error(), s.mainWindow)
+				return
+			}
+			job := CleanupJob{
+				Name:            strings.TrimSpace(nameEntry.Text),
+				MinSizeMB:       float64(f.MinSize) / 1048576,
+				NotModifiedDays: f.NotModifiedDays,
+				NotAccessedDays: f.NotAccessedDays,
+				Include:         f.IncludePatterns,
+				Exclude:         f.ExcludePatterns,
+				Action:          actionSelect.Selected,
+				Every:           strings.TrimSpace(everyEntry.Text),
+				CatchUp:         catchUpCheck.Checked,
+				AllowCaution:    cautionCheck.Checked,
+				Enabled:         true,
+			}
+			if categorySelect.Selected != "All" {
+				job.Category = categorySelect.Selected
+			}
+			for _, line := range strings.Split(targetsEntry.Text, "\n") {
+				if trim := strings.TrimSpace(line); trim != "" {
+					job.Targets = append(job.Targets, trim)
+				}
+			}
+			if err := validateCleanupJob(job); err != nil {
+				dialog.ShowInformation("Invalid Input", err.Error(), s.mainWindow)
+				return
+			}
+			for _, j := range jobs {
+				if j.Name == job.Name {
+					dialog.ShowInformation("Invalid Input", "A job named "+job.Name+" already exists.", s.mainWindow)
+					return
+				}
+			}
+			jobs = append(jobs, job)
+			saveJobs()
+		}, s.mainWindow)
+	})
+
+	runNowBtn := s.commandButton(toolJobs, "Run Now", nil, func() {
+		if selectedJob < 0 || selectedJob >= len(jobs) {
+			dialog.ShowInformation("No Job Selected", "Please select a job.", s.mainWindow)
+			return
+		}
+		job := jobs[selectedJob]
+		run := func() {
+			// a job must not run in two processes at once
+			if owner, pid := s.jobs.claim(); !owner {
+				msg := "Another process runs the scheduled jobs."
+				if pid != 0 {
+					msg = fmt.Sprintf("Scheduled jobs are run by process %d.", pid)
+				}
+				dialog.ShowInformation("Job Not Run", msg+"\nStop it to run "+job.Name+" from here.", s.mainWindow)
+				return
+			}
+			go func() {
+				if !s.jobs.runJob(job, "run manually") {
+					s.postUI(func() {
+						dialog.ShowInformation("Job Running", job.Name+" is already running.", s.mainWindow)
+					})
+				}
+			}()
+		}
+		if job.Action == jobActionReport {
+			run()
+			return
+		}
+		dialog.ShowConfirm("Run Job", fmt.Sprintf("Run %s now? Matching files will be %sd.", job.Name, job.Action), func(c bool) {
+			if c {
+				run()
+			}
+		}, s.mainWindow)
+	})
+
+	toggleBtn := s.commandButton(toolJobs, "Enable/Disable", nil, func() {
+		if selectedJob < 0 || selectedJob >= len(jobs) {
+			dialog.ShowInformation("No Job Selected", "Please select a job.", s.mainWindow)
+			return
+		}
+		jobs[selectedJob].Enabled = !jobs[selectedJob].Enabled
+		saveJobs()
+	})
+
+	removeBtn := s.commandButton(toolJobs, "Remove Job", nil, func() {
+		if selectedJob < 0 || selectedJob >= len(jobs) {
+			dialog.ShowInformation("No Job Selected", "Please select a job.", s.mainWindow)
+			return
+		}
+		jobs = append(jobs[:selectedJob], jobs[selectedJob+1:]...)
+		selectedJob = -1
+		jobList.UnselectAll()
+		saveJobs()
+	})
+
+	ownerLabel := widget.NewLabel("")
+	updateOwner := func() {
+		owner, pid := s.jobs.ownerStatus()
+		switch {
+		case owner:
+			ownerLabel.SetText("Jobs run in this window while it is open.")
+		case pid != 0:
+			ownerLabel.SetText(fmt.Sprintf("Jobs are run by process %d (daemon).", pid))
+		default:
+			ownerLabel.SetText("Jobs are not running.")
+		}
+	}
+	updateOwner()
+
+	runs := loadJobRuns()
+	runList := widget.NewList(
+		func() int { return len(runs) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			// newest first
+			r := runs[len(runs)-1-id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s  %s", r.Started, describeJobRun(r)))
+		},
+	)
+	refreshRuns := s.commandButton(toolJobs, "Refresh", nil, func() {
+		runs = loadJobRuns()
+		runList.Refresh()
+		updateOwner()
+	})
+
+	s.jobs.mu.Lock()
+	s.jobs.onRun = func(r JobRun) {
+		latest := loadJobRuns()
+		s.postUI(func() {
+			runs = latest
+			runList.Refresh()
+			if r.Processed > 0 || len(r.Errors) > 0 {
+				fyne.CurrentApp().SendNotification(fyne.NewNotification("Cleanup job "+r.Job, describeJobRun(r)))
+			}
+		})
+	}
+	s.jobs.mu.Unlock()
+
+	jobsPane := container.NewBorder(
+		container.NewVBox(widget.NewLabel("Scheduled Cleanup Jobs"), ownerLabel),
+		container.NewHBox(addBtn, runNowBtn, toggleBtn, removeBtn, layout.NewSpacer()),
+		nil, nil,
+		jobList,
+	)
+	runsPane := container.NewBorder(
+		container.NewHBox(widget.NewLabel("Job Runs"), layout.NewSpacer(), refreshRuns),
+		nil, nil, nil,
+		runList,
+	)
+	split := container.NewVSplit(jobsPane, runsPane)
+	split.Offset = 0.5
+	return split
+}
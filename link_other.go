@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// isLink reports whether path is a symbolic link.
+func isLink(path string, info os.FileInfo) bool {
+	return info.Mode()&os.ModeSymlink != 0
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// isLink reports whether path is a symbolic link or a junction. Go reports
+// junctions as irregular folders, but also other reparse points such as
+// cloud folders, so the reparse tag tells them apart.
+func isLink(path string, info os.FileInfo) bool {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return true
+	}
+	if !info.IsDir() || info.Mode()&os.ModeIrregular == 0 {
+		return false
+	}
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	var data windows.Win32finddata
+	h, err := windows.FindFirstFile(name, &data)
+	if err != nil {
+		return false
+	}
+	windows.FindClose(h)
+	return data.FileAttributes&windows.FILE_ATTRIBUTE_REPARSE_POINT != 0 && data.Reserved0 == windows.IO_REPARSE_TAG_MOUNT_POINT
+}
@@ -4,7 +4,6 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -14,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,35 +32,100 @@ import (
 	"github.com/shirou/gopsutil/v3/mem"
 )
 
-const passwordFilePath = "passwords.json" // File path for storing passwords
-const encryptionKey = "a16byteslongkey!"  // Must be 16, 24, or 32 bytes long
+const passwordFilePath = "passwords.json"              // Default password vault, see Settings.VaultPath
+const encryptionKey = "a16byteslongkey!"               // Must be 16, 24, or 32 bytes long
+const deletionHistoryFilePath = "deletion_history.log" // Deletion history, one JSON record per line
 
 // ---------------------------------------------------------------------
-//  1) Windows Directories
+//  1) Scan Targets
 // ---------------------------------------------------------------------
 
-var userProfile = os.Getenv("USERPROFILE")
+// scanTarget is one directory for the Space Cleaner to scan, with the filter
+// that applies to it (the form filters plus the cleanup target's own rules).
+type scanTarget struct {
+	dir     string
+	filter  FileFilter
+	caution bool // a cleanup target marked caution
+}
+
+// ---------------------------------------------------------------------
+//  2) App Theme
+// ---------------------------------------------------------------------
+
+const (
+	themeDark         = "Dark"
+	themeLight        = "Light"
+	themeSystem       = "System"        // follows the OS light/dark setting
+	themeHighContrast = "High contrast" // black background, white text, bright accent
+)
+
+var themeNames = []string{themeDark, themeLight, themeSystem, themeHighContrast}
 
-func init() {
-	if userProfile == "" {
-		userProfile = `C:\Users\Default`
+const defaultAccentColor = "#FFA500"
+
+// AppTheme is the theme built from the Settings. Setting a new AppTheme on
+// the app restyles every window immediately.
+type AppTheme struct {
+	mode     string
+	accent   color.NRGBA
+	padding  float32
+	textSize float32
+}
+
+func newAppTheme(st Settings) *AppTheme {
+	accent, err := parseHexColor(st.AccentColor)
+	if err != nil {
+		accent, _ = parseHexColor(defaultAccentColor)
 	}
+	return &AppTheme{mode: st.Theme, accent: accent, padding: st.Padding, textSize: st.TextSize}
 }
 
-var (
-	RECOMM_DIR_DOWNLOADS = filepath.Join(userProfile, "Downloads")
-	RECOMM_DIR_TEMP      = `C:\Windows\Temp`
-	RECOMM_DIR_PROGRAMS  = `C:\Program Files`
-	RECOMM_DIR_APPDATA   = filepath.Join(userProfile, "AppData", "Local")
-)
+// parseHexColor reads "#RRGGBB".
+func parseHexColor(s string) (color.NRGBA, error) {
+	var c color.NRGBA
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 {
+		return c, fmt.Errorf("colour must look like #RRGGBB")
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return c, fmt.Errorf("colour must look like #RRGGBB")
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
 
-// ---------------------------------------------------------------------
-//  2) Custom Dark Theme
-// ---------------------------------------------------------------------
+func formatHexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02X%02X%02X", n.R, n.G, n.B)
+}
 
-type MyDarkTheme struct{}
+func withAlpha(c color.NRGBA, a uint8) color.NRGBA {
+	c.A = a
+	return c
+}
+
+func (m *AppTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNamePrimary, theme.ColorNameHyperlink:
+		return m.accent
+	case theme.ColorNameFocus:
+		return withAlpha(m.accent, 180)
+	case theme.ColorNameSelection:
+		return withAlpha(m.accent, 100)
+	}
+
+	switch m.mode {
+	case themeHighContrast:
+		return highContrastColor(name, variant)
+	case themeLight:
+		return theme.DefaultTheme().Color(name, theme.VariantLight)
+	case themeSystem:
+		return theme.DefaultTheme().Color(name, variant)
+	}
+	return darkColor(name)
+}
 
-func (m *MyDarkTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+func darkColor(name fyne.ThemeColorName) color.Color {
 	switch name {
 	case theme.ColorNameBackground:
 		return color.RGBA{R: 30, G: 30, B: 30, A: 255}
@@ -74,25 +139,58 @@ func (m *MyDarkTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant)
 		return color.RGBA{R: 220, G: 220, B: 220, A: 255}
 	case theme.ColorNameHover:
 		return color.RGBA{R: 50, G: 50, B: 50, A: 255}
-	case theme.ColorNameFocus:
-		return color.RGBA{R: 255, G: 165, B: 0, A: 180}
 	case theme.ColorNameScrollBar:
 		return color.RGBA{R: 60, G: 60, B: 60, A: 255}
 	}
-	return theme.DefaultTheme().Color(name, variant)
+	return theme.DefaultTheme().Color(name, theme.VariantDark)
 }
-func (m *MyDarkTheme) Font(style fyne.TextStyle) fyne.Resource {
+
+func highContrastColor(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNameBackground, theme.ColorNameButton, theme.ColorNameInputBackground,
+		theme.ColorNameMenuBackground, theme.ColorNameOverlayBackground, theme.ColorNameHeaderBackground:
+		return color.Black
+	case theme.ColorNameForeground, theme.ColorNamePlaceHolder, theme.ColorNameInputBorder,
+		theme.ColorNameSeparator, theme.ColorNameScrollBar:
+		return color.White
+	case theme.ColorNameDisabled:
+		return color.RGBA{R: 190, G: 190, B: 190, A: 255}
+	case theme.ColorNameDisabledButton:
+		return color.RGBA{R: 40, G: 40, B: 40, A: 255}
+	case theme.ColorNameHover, theme.ColorNamePressed:
+		return color.RGBA{R: 60, G: 60, B: 60, A: 255}
+	case theme.ColorNameShadow:
+		return color.Transparent
+	}
+	return theme.DefaultTheme().Color(name, theme.VariantDark)
+}
+
+func (m *AppTheme) Font(style fyne.TextStyle) fyne.Resource {
 	return theme.DefaultTheme().Font(style)
 }
-func (m *MyDarkTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+func (m *AppTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
 	return theme.DefaultTheme().Icon(name)
 }
-func (m *MyDarkTheme) Size(name fyne.ThemeSizeName) float32 {
+func (m *AppTheme) Size(name fyne.ThemeSizeName) float32 {
 	switch name {
 	case theme.SizeNamePadding:
-		return 8
+		return m.padding
+	case theme.SizeNameInnerPadding:
+		return m.padding
+	case theme.SizeNameText:
+		return m.textSize
+	case theme.SizeNameHeadingText:
+		return m.textSize * 1.7
+	case theme.SizeNameSubHeadingText:
+		return m.textSize * 1.3
+	case theme.SizeNameCaptionText:
+		return m.textSize * 0.85
 	case theme.SizeNameScrollBar:
 		return 10
+	case theme.SizeNameInputBorder:
+		if m.mode == themeHighContrast {
+			return 2
+		}
 	}
 	return theme.DefaultTheme().Size(name)
 }
@@ -105,20 +203,35 @@ type DeletionRecord struct {
 	Timestamp string
 	FilePath  string
 	Method    string // e.g. "Duplicate Finder" or "Space Cleaner"
+	PlanID    string `json:",omitempty"` // set when deleted by executing a deletion plan
 }
 
 type FileItem struct {
-	filePath string
-	check    *widget.Check
+	filePath  string
+	size      int64
+	note      string // e.g. the similar-image group
+	selected  bool
+	reference bool      // in a reference folder, so never selected
+	modTime   time.Time // when scanned, to notice changes before deleting
+	removed   bool      // gone since the scan, noticed in watch mode
+	archive   string    // archive holding the file, whose path is then virtual
+	partial   bool      // a folder with files the scan left out
 }
 
 type LargeFileItem struct {
 	filePath string
 	size     int64
-	check    *widget.Check
+	category string
+	selected bool
+	removed  bool // gone since the scan, noticed in watch mode
 }
 
 type FileScanner struct {
+	// mu guards the scan results and deletion history below; background
+	// goroutines deliver their results through postUI (see uiqueue.go)
+	mu        sync.Mutex
+	uiUpdates *uiQueue
+
 	// Deletion History
 	deletionRecords []DeletionRecord
 
@@ -137,27 +250,71 @@ type FileScanner struct {
 	leftNav fyne.CanvasObject
 	rightUI fyne.CanvasObject
 
-	duplicateListVBox *fyne.Container
-	// Pagination for duplicates
-	dfPageSize    int
-	dfCurrentPage int
-	dfTotalPages  int
-	dfPageLabel   *widget.Label
-	dfPrevBtn     *widget.Button
-	dfNextBtn     *widget.Button
+	keyMods *keyModifiers // Shift state for range selection in the file lists
+
+	// Keyboard shortcuts and the command palette (see commands.go)
+	tools          []toolEntry
+	commands       []*Command
+	boundShortcuts map[string]bool
+
+	// The running scan; closing its dialog cancels it
+	scanGen int
+	scanDlg dialog.Dialog
+
+	dfList       *SelectableList
+	dfCountLabel *widget.Label
+	dfSortSelect *widget.Select
+
+	// Similar images mode (see imagehash.go)
+	imageHashes   map[string]uint64
+	dfPreview     *fyne.Container // thumbnails of the clicked image's group
+	dfPreviewPath string
+
+	// Folders of the last Duplicate Finder scan and their roles (see roots.go)
+	dfRoots      []ScanRoot
+	dfRootsLabel *widget.Label
+
+	// How the last Duplicate Finder results were found (see hashers.go)
+	dfAlgorithm   string
+	dfVerified    bool
+	hashes        *HashCache
+	hashCacheOnce sync.Once
+
+	filterForms []*filterForm // share saved presets (see presets.go)
+
+	// Watch mode keeps results up to date (see watch.go)
+	dfFilter                   FileFilter // filter of the last Duplicate Finder scan
+	dfIndex                    *hashIndex // every file the last exact-duplicate scan compared
+	dfWatcher                  *resultWatcher
+	scTargets                  []scanTarget // targets of the last Space Cleaner scan
+	scWatcher                  *resultWatcher
+	dfWatchCheck, scWatchCheck *widget.Check
 
 	largeFileItems []*LargeFileItem
-	// Pagination for space cleaner
-	scPageSize    int
-	scCurrentPage int
-	scTotalPages  int
-	scPageLabel   *widget.Label
-	scPrevBtn     *widget.Button
-	scNextBtn     *widget.Button
+	scVisible      []*LargeFileItem // largeFileItems in the current category and folder
+	scList         *SelectableList
+	scCountLabel   *widget.Label
+	scCategory     string // "" shows every category
+	scCategorySel  *widget.Select
+	scScope        string // only list files under this directory ("" = all)
+	scScopeLabel   *widget.Label
+	scTreemap      *Treemap
 
 	passwordManagerRoot fyne.CanvasObject
 
 	systemInfoRoot fyne.CanvasObject
+
+	startupRoot fyne.CanvasObject
+	jobsRoot    fyne.CanvasObject
+
+	settings     Settings
+	settingsRoot fyne.CanvasObject
+
+	// System monitoring
+	sampler *SystemSampler
+	alerts  *AlertManager
+	metrics *MetricsStore
+	jobs    *JobScheduler
 }
 
 // ---------------------------------------------------------------------
@@ -165,23 +322,52 @@ type FileScanner struct {
 // ---------------------------------------------------------------------
 
 func main() {
+	// Headless mode: only run the scheduled cleanup jobs
+	if len(os.Args) > 1 && os.Args[1] == "--daemon" {
+		runJobDaemon()
+		return
+	}
+
+	settings, err := loadSettings()
+	if err != nil {
+		fmt.Println("Error loading settings:", err)
+	}
+
 	a := app.New()
-	a.Settings().SetTheme(&MyDarkTheme{})
+	a.Settings().SetTheme(newAppTheme(settings))
 
 	w := a.NewWindow("Windows Optimization Tool")
 	w.Resize(fyne.NewSize(1200, 700))
 
 	scanner := &FileScanner{
-		deletionRecords:  []DeletionRecord{},
+		deletionRecords:  loadDeletionHistory(),
 		allDuplicates:    map[string][]string{},
 		allFileItems:     []*FileItem{},
 		largeFileItems:   []*LargeFileItem{},
-		lastSelectedSort: "Path",
+		lastSelectedSort: settings.DuplicateSort,
 		mainWindow:       w,
-		// 20 per page for both
-		dfPageSize: 20,
-		scPageSize: 20,
+		settings:         settings,
+		keyMods:          trackKeyModifiers(w),
+		uiUpdates:        newUIQueue(),
 	}
+	go scanner.runUIUpdates()
+
+	// Background sampler feeding the alert rules
+	rules, err := loadAlertRules()
+	if err != nil {
+		fmt.Println("Error loading alert rules:", err)
+		rules = defaultAlertRules()
+	}
+	scanner.sampler = newSystemSampler(sampleInterval)
+	scanner.alerts = newAlertManager(rules)
+	scanner.sampler.wantProcesses = scanner.alerts.watchesProcesses
+	scanner.sampler.subscribe(scanner.alerts.evaluate)
+	scanner.metrics, err = loadMetricsStore()
+	if err != nil {
+		fmt.Println("Error loading metrics history:", err)
+	}
+	scanner.sampler.subscribe(scanner.metrics.record)
+	scanner.jobs = newJobScheduler()
 
 	// Initialize left menu and individual tabs
 	scanner.leftNav = scanner.makeLeftMenu()
@@ -190,17 +376,27 @@ func main() {
 	scanner.historyRoot = scanner.setupHistoryUI()
 	scanner.passwordManagerRoot = scanner.setupPasswordManagerUI() // Password Manager
 	scanner.systemInfoRoot = scanner.setupSystemInfoUI()           // System Info
+	scanner.startupRoot = scanner.setupStartupUI()                 // Startup Items
+	scanner.jobsRoot = scanner.setupJobsUI()                       // Scheduled Jobs
+	scanner.settingsRoot = scanner.setupSettingsUI()               // Settings
 
 	// Set the default right UI
 	scanner.rightUI = scanner.duplicateFinderRoot
 
 	// Configure the split layout
 	scanner.split = container.NewHSplit(scanner.leftNav, scanner.rightUI)
-	scanner.split.Offset = 0.2
+	scanner.split.Offset = settings.SplitOffset
 
 	// Set the content and start the app
 	w.SetContent(scanner.split)
+	scanner.sampler.start()
+	scanner.jobs.start()
 	w.ShowAndRun()
+	scanner.jobs.shutdown()
+	scanner.sampler.shutdown()
+	if err := scanner.metrics.save(); err != nil {
+		fmt.Println("Error saving metrics history:", err)
+	}
 }
 
 // ---------------------------------------------------------------------
@@ -208,30 +404,36 @@ func main() {
 // ---------------------------------------------------------------------
 
 func (s *FileScanner) makeLeftMenu() fyne.CanvasObject {
-	dupBtn := widget.NewButton("Duplicate Finder", func() {
-		s.switchRightContent(s.duplicateFinderRoot)
-	})
-	spaceCleanerBtn := widget.NewButton("Space Cleaner", func() {
-		s.switchRightContent(s.spaceCleanerRoot)
-	})
-	historyBtn := widget.NewButton("Deletion History", func() {
-		s.switchRightContent(s.historyRoot)
-	})
-	passwordManagerBtn := widget.NewButton("Password Manager", func() {
-		s.switchRightContent(s.passwordManagerRoot)
-	})
-	systemInfoBtn := widget.NewButton("System Info", func() {
-		s.switchRightContent(s.systemInfoRoot)
-	})
+	s.tools = []toolEntry{
+		{name: toolDuplicateFinder, key: fyne.Key1, root: &s.duplicateFinderRoot},
+		{name: toolSpaceCleaner, key: fyne.Key2, root: &s.spaceCleanerRoot},
+		{name: toolHistory, key: fyne.Key3, root: &s.historyRoot, show: func() {
+			// reload so deletions made by scheduled jobs show up
+			records := loadDeletionHistory()
+			s.mu.Lock()
+			s.deletionRecords = records
+			s.mu.Unlock()
+			s.historyRoot = s.setupHistoryUI()
+			s.switchRightContent(s.historyRoot)
+		}},
+		{name: toolPasswordManager, key: fyne.Key4, root: &s.passwordManagerRoot},
+		{name: toolSystemInfo, key: fyne.Key5, root: &s.systemInfoRoot},
+		{name: toolStartup, key: fyne.Key6, root: &s.startupRoot},
+		{name: toolJobs, key: fyne.Key7, root: &s.jobsRoot},
+		{name: toolSettings, key: fyne.KeyComma, root: &s.settingsRoot},
+	}
 
-	return container.NewVBox(
-		dupBtn,
-		spaceCleanerBtn,
-		historyBtn,
-		passwordManagerBtn,
-		systemInfoBtn,
-		layout.NewSpacer(),
-	)
+	menu := container.NewVBox()
+	for _, t := range s.tools {
+		show := func() { s.showTool(t.name) }
+		s.addCommand(&Command{Name: "Go to " + t.name, Shortcut: ctrlKey(t.key), Run: show})
+		if t.name == toolSettings {
+			menu.Add(layout.NewSpacer())
+		}
+		menu.Add(widget.NewButton(t.name, show))
+	}
+	s.addGlobalCommands()
+	return menu
 }
 
 func (s *FileScanner) switchRightContent(content fyne.CanvasObject) {
@@ -251,6 +453,7 @@ func (s *FileScanner) setupDuplicateFinderUI() fyne.CanvasObject {
 	dirEntry := widget.NewMultiLineEntry()
 	dirEntry.SetPlaceHolder("Enter directory path...")
 	dirEntry.Wrapping = fyne.TextWrapWord
+	dirEntry.SetText(s.settings.DuplicateDir)
 	dirWrap := container.NewGridWrap(fyne.NewSize(300, 60), dirEntry)
 
 	filterEntry := widget.NewMultiLineEntry()
@@ -260,7 +463,29 @@ func (s *FileScanner) setupDuplicateFinderUI() fyne.CanvasObject {
 
 	filterLabel := widget.NewLabel("Filter by extension")
 
-	selectDirBtn := widget.NewButton("Select Directory", func() {
+	// Further rules shared with the Space Cleaner, edited in a dialog
+	var dfFilter FileFilter
+	dfFilterForm := s.newFilterForm(dfFilter)
+	dfFilterLabel := widget.NewLabel(dfFilter.summary())
+	filtersBtn := s.commandButton(toolDuplicateFinder, "Filters...", nil, func() {
+		dfFilterForm.show(dfFilter)
+		dlg := dialog.NewCustomConfirm("Duplicate Finder Filters", "Apply", "Cancel", dfFilterForm.content(), func(ok bool) {
+			if !ok {
+				return
+			}
+			f, err := dfFilterForm.read()
+			if err != nil {
+				dialog.ShowError(err, s.mainWindow)
+				return
+			}
+			dfFilter = f
+			dfFilterLabel.SetText(dfFilter.summary())
+		}, s.mainWindow)
+		dlg.Resize(fyne.NewSize(600, 500))
+		dlg.Show()
+	})
+
+	selectDirBtn := s.commandButton(toolDuplicateFinder, "Select Directory", nil, func() {
 		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err != nil || uri == nil {
 				return
@@ -273,6 +498,14 @@ func (s *FileScanner) setupDuplicateFinderUI() fyne.CanvasObject {
 		}, s.mainWindow)
 	})
 
+	// Further folders to compare against, e.g. a reference archive
+	s.dfRootsLabel = widget.NewLabel(rootsSummary(s.settings.DuplicateRoots))
+	rootsBtn := s.commandButton(toolDuplicateFinder, "More Folders...", nil, func() {
+		s.showRootsDialog(func() {
+			s.dfRootsLabel.SetText(rootsSummary(s.settings.DuplicateRoots))
+		})
+	})
+
 	topBar := container.NewHBox(
 		container.NewVBox(
 			widget.NewForm(&widget.FormItem{
@@ -280,64 +513,229 @@ func (s *FileScanner) setupDuplicateFinderUI() fyne.CanvasObject {
 				Widget: dirWrap,
 			}),
 		),
-		selectDirBtn,
+		container.NewVBox(selectDirBtn, rootsBtn, s.dfRootsLabel),
 		container.NewVBox(filterLabel, filterWrap),
+		container.NewVBox(filtersBtn, dfFilterLabel),
 	)
 
-	s.duplicateListVBox = container.NewVBox()
-	scroll := container.NewScroll(s.duplicateListVBox)
-	scroll.SetMinSize(fyne.NewSize(0, 380))
+	s.dfList = newSelectableList(s.keyMods,
+		func() int {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.allFileItems)
+		},
+		func(i int) string {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			fi := s.allFileItems[i]
+			if fi.note != "" {
+				return fmt.Sprintf("%s (%s) — %s", fi.filePath, formatSize(fi.size), fi.note)
+			}
+			return fmt.Sprintf("%s (%s)", fi.filePath, formatSize(fi.size))
+		},
+		func(i int) bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.allFileItems[i].selected
+		},
+		func(i int, b bool) {
+			s.mu.Lock()
+			if fi := s.allFileItems[i]; !fi.reference && !fi.removed {
+				fi.selected = b
+			}
+			s.mu.Unlock()
+		},
+	)
+	s.dfCountLabel = widget.NewLabel("")
+	s.dfList.OnSelectionChanged = s.updateDuplicateCount
+	s.addPageCommands(toolDuplicateFinder, s.dfList)
+
+	// Similar images: perceptual hash, distance threshold and thumbnail preview
+	s.dfPreview = container.NewGridWrap(fyne.NewSize(imageThumbnailSize+20, imageThumbnailSize+110))
+	previewBox := container.NewBorder(widget.NewLabel("Click an image to compare its group"), nil, nil, nil,
+		container.NewVScroll(s.dfPreview))
+	s.dfList.OnRowToggled = func(i int) {
+		s.mu.Lock()
+		path := s.allFileItems[i].filePath
+		s.mu.Unlock()
+		s.showSimilarGroup(path)
+	}
+
+	hashSelect := widget.NewSelect(imageHashNames, nil)
+	hashSelect.Selected = defaultImageHash
+	distanceLabel := widget.NewLabel("")
+	distanceSlider := widget.NewSlider(0, maxImageDistance)
+	distanceSlider.Step = 1
+	distanceSlider.OnChanged = func(v float64) {
+		distanceLabel.SetText(fmt.Sprintf("Max distance: %d", int(v)))
+	}
+	distanceSlider.SetValue(defaultMaxDistance)
+	similarBox := container.NewHBox(
+		widget.NewLabel("Hash:"), hashSelect,
+		distanceLabel, container.NewGridWrap(fyne.NewSize(200, distanceSlider.MinSize().Height), distanceSlider),
+	)
+
+	// Same recording in other formats: minimum confidence for a group
+	confidenceLabel := widget.NewLabel("")
+	confidenceSlider := widget.NewSlider(30, 100)
+	confidenceSlider.Step = 5
+	confidenceSlider.OnChanged = func(v float64) {
+		confidenceLabel.SetText(fmt.Sprintf("Min confidence: %d%%", int(v)))
+	}
+	confidenceSlider.SetValue(defaultMediaConfidence)
+	mediaBox := container.NewHBox(
+		confidenceLabel, container.NewGridWrap(fyne.NewSize(200, confidenceSlider.MinSize().Height), confidenceSlider),
+	)
+
+	// Duplicate folders: minimum share of content, 100% for identical only
+	overlapLabel := widget.NewLabel("")
+	overlapSlider := widget.NewSlider(50, 100)
+	overlapSlider.Step = 5
+	overlapSlider.OnChanged = func(v float64) {
+		overlapLabel.SetText(fmt.Sprintf("Min overlap: %d%%", int(v)))
+	}
+	overlapSlider.SetValue(defaultFolderOverlap)
+	folderBox := container.NewHBox(
+		overlapLabel, container.NewGridWrap(fyne.NewSize(200, overlapSlider.MinSize().Height), overlapSlider),
+	)
 
-	findDuplicatesBtn := widget.NewButton("Find Duplicates", func() {
-		s.duplicateListVBox.Objects = nil
+	// Exact duplicates: also compare the files inside archives
+	archivesCheck := widget.NewCheck("Scan inside archives", nil)
+
+	modeExact, modeSimilar, modeMedia, modeFolders := "Exact duplicates", "Similar images", "Same audio/video", "Duplicate folders"
+	modeRadio := widget.NewRadioGroup([]string{modeExact, modeSimilar, modeMedia, modeFolders}, func(mode string) {
+		similarBox.Hide()
+		previewBox.Hide()
+		mediaBox.Hide()
+		folderBox.Hide()
+		archivesCheck.Hide()
+		switch mode {
+		case modeExact:
+			archivesCheck.Show()
+		case modeSimilar:
+			similarBox.Show()
+			previewBox.Show()
+		case modeMedia:
+			mediaBox.Show()
+		case modeFolders:
+			folderBox.Show()
+		}
+	})
+	modeRadio.Horizontal = true
+	modeRadio.Required = true
+	modeRadio.SetSelected(modeExact)
+
+	findDuplicatesBtn := s.commandButton(toolDuplicateFinder, "Find Duplicates", shortcutScan, func() {
+		s.watchDuplicates(false)
+		s.mu.Lock()
 		s.allFileItems = nil
 		s.allDuplicates = map[string][]string{}
-		s.dfCurrentPage = 0
+		s.imageHashes = nil
+		s.dfIndex = nil
+		s.mu.Unlock()
+		s.dfPreview.Objects = nil
+		s.dfPreview.Refresh()
+		s.refreshDuplicates()
 
 		dirPath := strings.TrimSpace(dirEntry.Text)
 		if dirPath == "" {
 			dialog.ShowInformation("Error", "Please enter or select a directory.", s.mainWindow)
 			return
 		}
-		s.showScanningDuplicates(dirPath, filterEntry.Text)
+		roots := s.duplicateRoots(dirPath)
+		filter := dfFilter
+		filter.Extensions = parseExtFilter(filterEntry.Text)
+		s.mu.Lock()
+		s.dfRoots, s.dfFilter = roots, filter
+		s.mu.Unlock()
+		switch modeRadio.Selected {
+		case modeSimilar:
+			s.showScanningSimilarImages(roots, filter, hashSelect.Selected, int(distanceSlider.Value))
+		case modeMedia:
+			s.showScanningMedia(roots, filter, confidenceSlider.Value/100)
+		case modeFolders:
+			s.showScanningFolders(roots, filter, overlapSlider.Value/100)
+		default:
+			s.showScanningDuplicates(roots, filter, archivesCheck.Checked)
+		}
 	})
 
-	deleteSelectedBtn := widget.NewButton("Delete Selected", func() {
+	dryRunCheck := widget.NewCheck("Dry run", nil)
+	s.dfWatchCheck = widget.NewCheck("Watch for changes", s.watchDuplicates)
+	verifyCheck := widget.NewCheck("Verify before delete", nil)
+	verifyCheck.SetChecked(true)
+
+	deleteSelectedBtn := s.commandButton(toolDuplicateFinder, "Delete Selected", shortcutDelete, func() {
 		toDelete := s.getCheckedFiles()
 		if len(toDelete) == 0 {
 			dialog.ShowInformation("No Files Selected", "Please select at least one file.", s.mainWindow)
 			return
 		}
-		dialog.ShowConfirm("Confirm Deletion", fmt.Sprintf("Delete %d file(s)?", len(toDelete)), func(c bool) {
-			if !c {
-				return
-			}
+		toDelete, ok := s.withoutArchiveEntries(toDelete)
+		if !ok {
+			return
+		}
+		if toDelete, ok = s.withoutPartialFolders(toDelete); !ok {
+			return
+		}
+		if dryRunCheck.Checked {
+			plan := s.duplicatePlan(toDelete, verifyCheck.Checked)
+			s.showPlanReview(plan, func(deleted []string) {
+				s.dropFileItems(deleted)
+				s.refreshDuplicates()
+			})
+			return
+		}
+		s.confirmGuarded("Confirm Deletion", "Delete", toDelete, func(allowed []string) {
 			var errs []string
-			var deletedCount int
-			for _, fp := range toDelete {
-				err := os.Remove(fp)
+			var deleted []string
+			if verifyCheck.Checked {
+				allowed, errs = s.verifyDuplicates(allowed)
+			} else {
+				allowed, errs = s.keepCopies(allowed)
+			}
+			for _, fp := range allowed {
+				err := removePath(fp)
 				if err != nil {
 					errs = append(errs, fmt.Sprintf("Failed to delete %s: %v", fp, err))
 				} else {
-					deletedCount++
+					deleted = append(deleted, fp)
 					s.addDeletionRecord(fp, "Duplicate Finder")
 				}
 			}
 			if len(errs) > 0 {
 				dialog.ShowError(fmt.Errorf(strings.Join(errs, "\n")), s.mainWindow)
 			}
-			dialog.ShowInformation("Deletion Complete", fmt.Sprintf("Deleted %d file(s).", deletedCount), s.mainWindow)
+			dialog.ShowInformation("Deletion Complete", fmt.Sprintf("Deleted %d file(s).", len(deleted)), s.mainWindow)
 			s.refreshDeletionTable()
+			s.dropFileItems(deleted)
 			s.refreshDuplicates()
-		}, s.mainWindow)
+		})
 	})
 
-	renameBtn := widget.NewButton("Rename Selected", func() {
+	linkBtn := s.commandButton(toolDuplicateFinder, "Link Selected", nil, func() {
+		toLink := s.getCheckedFiles()
+		if len(toLink) == 0 {
+			dialog.ShowInformation("No Files Selected", "Please select at least one file.", s.mainWindow)
+			return
+		}
+		toLink, ok := s.withoutArchiveEntries(toLink)
+		if !ok {
+			return
+		}
+		s.linkSelected(toLink)
+	})
+
+	renameBtn := s.commandButton(toolDuplicateFinder, "Rename Selected", nil, func() {
 		toRename := s.getCheckedFiles()
 		if len(toRename) == 0 {
 			dialog.ShowInformation("No Files Selected", "Please check at least one file.", s.mainWindow)
 			return
 		}
+		toRename, ok := s.withoutArchiveEntries(toRename)
+		if !ok {
+			return
+		}
 		dialog.ShowEntryDialog(
 			"Rename Selected Files",
 			"Enter prefix for renamed files:",
@@ -345,181 +743,169 @@ func (s *FileScanner) setupDuplicateFinderUI() fyne.CanvasObject {
 				if prefix == "" {
 					return
 				}
-				var renamedCount int
-				var errs []string
-				for _, fp := range toRename {
-					dir := filepath.Dir(fp)
-					ext := filepath.Ext(fp)
-					oldName := filepath.Base(fp)
-					newName := prefix + "_" + oldName + ext
-					newPath := filepath.Join(dir, newName)
-					err := os.Rename(fp, newPath)
-					if err != nil {
-						errs = append(errs, fmt.Sprintf("Failed to rename %s: %v", fp, err))
-					} else {
-						renamedCount++
-					}
-				}
-				if len(errs) > 0 {
-					dialog.ShowError(fmt.Errorf(strings.Join(errs, "\n")), s.mainWindow)
-				}
-				dialog.ShowInformation("Rename Complete", fmt.Sprintf("Renamed %d file(s).", renamedCount), s.mainWindow)
-				s.refreshDuplicates()
+				s.confirmGuarded("Confirm Rename", "Rename", toRename, func(allowed []string) {
+					s.renameFiles(allowed, prefix)
+				})
 			},
 			s.mainWindow,
 		)
 	})
 
+	exportBtn := s.commandButton(toolDuplicateFinder, "Export Results", nil, s.exportDuplicates)
+
 	sortLabel := widget.NewLabel("Sort By:")
 	sortSelect := widget.NewSelect([]string{"Path", "Size"}, func(val string) {
 		s.lastSelectedSort = val
 		s.refreshDuplicates()
 	})
 	sortSelect.PlaceHolder = "(Select)"
+	sortSelect.Selected = s.lastSelectedSort
+	s.dfSortSelect = sortSelect
 
-	selectAllBtn := widget.NewButton("Select All", func() {
-		for _, fi := range s.allFileItems {
-			if fi.check != nil {
-				fi.check.SetChecked(true)
-			}
-		}
+	selectAllBtn := s.commandButton(toolDuplicateFinder, "Select All", shortcutSelectAll, func() {
+		s.dfList.setAll(true)
 	})
-	deselectAllBtn := widget.NewButton("Deselect All", func() {
-		for _, fi := range s.allFileItems {
-			if fi.check != nil {
-				fi.check.SetChecked(false)
-			}
-		}
-	})
-
-	// Pagination row for duplicates
-	s.dfPageLabel = widget.NewLabel("")
-	s.dfPrevBtn = widget.NewButton("<<< Prev Page", func() {
-		if s.dfCurrentPage > 0 {
-			s.dfCurrentPage--
-			s.refreshDuplicates()
-		}
-	})
-	s.dfNextBtn = widget.NewButton("Next Page >>>", func() {
-		if s.dfCurrentPage < s.dfTotalPages-1 {
-			s.dfCurrentPage++
-			s.refreshDuplicates()
-		}
+	deselectAllBtn := s.commandButton(toolDuplicateFinder, "Deselect All", shortcutSelectNone, func() {
+		s.dfList.setAll(false)
 	})
-	dfPagingBox := container.NewHBox(s.dfPrevBtn, s.dfPageLabel, s.dfNextBtn)
 
 	bottomBar := container.NewVBox(
+		container.NewHBox(modeRadio, archivesCheck, similarBox, mediaBox, folderBox),
 		container.NewHBox(
 			findDuplicatesBtn,
 			deleteSelectedBtn,
+			dryRunCheck,
+			verifyCheck,
+			s.dfWatchCheck,
+			linkBtn,
 			renameBtn,
 			sortLabel,
 			sortSelect,
 			selectAllBtn,
 			deselectAllBtn,
 			layout.NewSpacer(),
+			exportBtn,
 		),
-		dfPagingBox,
+		s.dfCountLabel,
 	)
-
-	// Initialize so the label says "Page 0 of 0"
-	s.dfTotalPages = 0
-	s.updateDuplicatePageLabel()
+	s.updateDuplicateCount()
 
 	return container.NewBorder(
 		topBar,
 		bottomBar,
 		nil,
-		nil,
-		scroll,
+		previewBox,
+		s.dfList.List,
 	)
 }
 
-// updateDuplicatePageLabel sets text & enables/disables next/prev
-func (s *FileScanner) updateDuplicatePageLabel() {
-	if s.dfTotalPages == 0 {
-		s.dfPageLabel.SetText("Page 0 of 0")
-		s.dfPrevBtn.Disable()
-		s.dfNextBtn.Disable()
-		return
-	}
-	s.dfPageLabel.SetText(fmt.Sprintf("Page %d of %d", s.dfCurrentPage+1, s.dfTotalPages))
-
-	s.dfPrevBtn.Disable()
-	s.dfNextBtn.Disable()
-
-	if s.dfCurrentPage > 0 {
-		s.dfPrevBtn.Enable()
-	}
-	if s.dfCurrentPage < s.dfTotalPages-1 {
-		s.dfNextBtn.Enable()
+// updateDuplicateCount shows how many files are listed and selected
+func (s *FileScanner) updateDuplicateCount() {
+	var n int
+	var size int64
+	s.mu.Lock()
+	total := len(s.allFileItems)
+	for _, fi := range s.allFileItems {
+		if fi.selected {
+			n++
+			size += fi.size
+		}
 	}
+	s.mu.Unlock()
+	s.dfCountLabel.SetText(fmt.Sprintf("%d duplicate file(s), %d selected (%s)", total, n, formatSize(size)))
 }
 
-// refreshDuplicates shows only the current page of s.allFileItems
+// refreshDuplicates sorts s.allFileItems and redraws the list
 func (s *FileScanner) refreshDuplicates() {
-	if s.duplicateListVBox == nil {
+	if s.dfList == nil {
 		return
 	}
-	s.duplicateListVBox.Objects = nil
-
-	if len(s.allFileItems) == 0 {
-		s.duplicateListVBox.Add(widget.NewLabel("No duplicate files found."))
-		s.duplicateListVBox.Refresh()
-		s.dfTotalPages = 1
-		s.dfCurrentPage = 0
-		s.updateDuplicatePageLabel()
-		return
-	}
-
-	// Sort if needed
+	s.mu.Lock()
 	if s.lastSelectedSort == "Size" {
-		sort.Slice(s.allFileItems, func(i, j int) bool {
-			return fileSize(s.allFileItems[i].filePath) < fileSize(s.allFileItems[j].filePath)
+		sort.SliceStable(s.allFileItems, func(i, j int) bool {
+			return s.allFileItems[i].size < s.allFileItems[j].size
 		})
 	} else {
-		sort.Slice(s.allFileItems, func(i, j int) bool {
+		sort.SliceStable(s.allFileItems, func(i, j int) bool {
 			return s.allFileItems[i].filePath < s.allFileItems[j].filePath
 		})
 	}
-
-	s.dfTotalPages = (len(s.allFileItems) + s.dfPageSize - 1) / s.dfPageSize
-	if s.dfCurrentPage >= s.dfTotalPages {
-		s.dfCurrentPage = s.dfTotalPages - 1
-	}
-	if s.dfCurrentPage < 0 {
-		s.dfCurrentPage = 0
-	}
-
-	start := s.dfCurrentPage * s.dfPageSize
-	end := start + s.dfPageSize
-	if end > len(s.allFileItems) {
-		end = len(s.allFileItems)
-	}
-
-	for i := start; i < end; i++ {
-		fi := s.allFileItems[i]
-		if fi.check == nil {
-			fi.check = widget.NewCheck(fi.filePath, func(bool) {})
-		}
-		s.duplicateListVBox.Add(fi.check)
-	}
-	s.duplicateListVBox.Refresh()
-
-	s.updateDuplicatePageLabel()
+	s.mu.Unlock()
+	s.dfList.Refresh()
 }
 
 // getCheckedFiles returns file paths for the currently checked items in Duplicate Finder
 func (s *FileScanner) getCheckedFiles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	var result []string
 	for _, fi := range s.allFileItems {
-		if fi.check != nil && fi.check.Checked {
+		if fi.selected {
 			result = append(result, fi.filePath)
 		}
 	}
 	return result
 }
 
+// dropFileItems removes deleted files from the Duplicate Finder list and
+// their groups. Groups left with a single file are dropped with it.
+func (s *FileScanner) dropFileItems(deleted []string) {
+	gone := make(map[string]bool)
+	for _, d := range deleted {
+		gone[d] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inGroup := make(map[string]bool)
+	for key, group := range s.allDuplicates {
+		var kept []string
+		for _, p := range group {
+			if !gone[p] {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) < 2 {
+			delete(s.allDuplicates, key)
+			continue
+		}
+		s.allDuplicates[key] = kept
+		for _, p := range kept {
+			inGroup[p] = true
+		}
+	}
+	var items []*FileItem
+	for _, fi := range s.allFileItems {
+		if inGroup[fi.filePath] {
+			items = append(items, fi)
+		}
+	}
+	s.allFileItems = items
+}
+
+// renameFiles prefixes the name of every file in paths
+func (s *FileScanner) renameFiles(paths []string, prefix string) {
+	var renamedCount int
+	var errs []string
+	for _, fp := range paths {
+		dir := filepath.Dir(fp)
+		ext := filepath.Ext(fp)
+		oldName := filepath.Base(fp)
+		newName := prefix + "_" + oldName + ext
+		newPath := filepath.Join(dir, newName)
+		err := os.Rename(fp, newPath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Failed to rename %s: %v", fp, err))
+		} else {
+			renamedCount++
+		}
+	}
+	if len(errs) > 0 {
+		dialog.ShowError(fmt.Errorf(strings.Join(errs, "\n")), s.mainWindow)
+	}
+	dialog.ShowInformation("Rename Complete", fmt.Sprintf("Renamed %d file(s).", renamedCount), s.mainWindow)
+	s.refreshDuplicates()
+}
+
 // helper to get file size
 func fileSize(path string) int64 {
 	st, err := os.Stat(path)
@@ -534,50 +920,193 @@ func fileSize(path string) int64 {
 // ---------------------------------------------------------------------
 
 func (s *FileScanner) setupSpaceCleanerUI() fyne.CanvasObject {
-	spaceContainer := container.NewVBox()
-	scroll := container.NewScroll(spaceContainer)
-	scroll.SetMinSize(fyne.NewSize(0, 400))
-
-	lbl := widget.NewLabel("Recommended Directories")
-
-	chkDownloads := widget.NewCheck(RECOMM_DIR_DOWNLOADS, nil)
-	chkTemp := widget.NewCheck(RECOMM_DIR_TEMP, nil)
-	chkPrograms := widget.NewCheck(RECOMM_DIR_PROGRAMS, nil)
-	chkAppData := widget.NewCheck(RECOMM_DIR_APPDATA, nil)
-
-	selectAllRecs := widget.NewButton("Select All Recommended", func() {
-		chkDownloads.SetChecked(true)
-		chkTemp.SetChecked(true)
-		chkPrograms.SetChecked(true)
-		chkAppData.SetChecked(true)
+	s.scList = newSelectableList(s.keyMods,
+		func() int {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.scVisible)
+		},
+		func(i int) string {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			lf := s.scVisible[i]
+			if lf.removed {
+				return fmt.Sprintf("%s (%.2f MB) [%s] — removed", lf.filePath, float64(lf.size)/1048576, lf.category)
+			}
+			return fmt.Sprintf("%s (%.2f MB) [%s]", lf.filePath, float64(lf.size)/1048576, lf.category)
+		},
+		func(i int) bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.scVisible[i].selected
+		},
+		func(i int, b bool) {
+			s.mu.Lock()
+			if !s.scVisible[i].removed {
+				s.scVisible[i].selected = b
+			}
+			s.mu.Unlock()
+		},
+	)
+	s.scCountLabel = widget.NewLabel("")
+	s.scList.OnSelectionChanged = s.updateSpaceCleanerCount
+	s.addPageCommands(toolSpaceCleaner, s.scList)
+
+	lbl := widget.NewLabel("Cleanup Targets")
+
+	// Targets come from the selected cleanup profile
+	profiles, profileErrs := loadCleanupProfiles()
+	for _, e := range profileErrs {
+		fmt.Println("Error loading cleanup profile:", e)
+	}
+	var targets []CleanupTarget
+	var targetChecks []*widget.Check
+	targetsBox := container.NewVBox()
+
+	showProfile := func(p CleanupProfile) {
+		targets = p.Targets
+		targetChecks = nil
+		targetsBox.Objects = nil
+		for _, t := range targets {
+			path := expandPathTemplate(t.Path)
+			chk := widget.NewCheck(fmt.Sprintf("[%s] %s — %s", t.Safety, t.Name, path), nil)
+			if _, err := os.Stat(path); err != nil {
+				chk.Text += " (not found)"
+				chk.Disable()
+			}
+			targetChecks = append(targetChecks, chk)
+			targetsBox.Add(chk)
+		}
+		targetsBox.Refresh()
+	}
+
+	profileNames := func() []string {
+		var names []string
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	profileSelect := widget.NewSelect(profileNames(), func(val string) {
+		for _, p := range profiles {
+			if p.Name == val {
+				showProfile(p)
+				return
+			}
+		}
 	})
+	if len(profiles) > 0 {
+		selected := profiles[defaultProfileIndex(profiles)].Name
+		for _, p := range profiles {
+			if p.Name == s.settings.DefaultProfile {
+				selected = p.Name
+			}
+		}
+		profileSelect.SetSelected(selected)
+	}
 
-	scanRecsBtn := widget.NewButton("Scan", func() {
-		spaceContainer.Objects = nil
-		s.largeFileItems = []*LargeFileItem{}
-		s.scCurrentPage = 0
+	importProfileBtn := s.commandButton(toolSpaceCleaner, "Import Profile", nil, func() {
+		dialog.ShowFileOpen(func(read fyne.URIReadCloser, err error) {
+			if err != nil || read == nil {
+				return
+			}
+			defer read.Close()
+			data, err := io.ReadAll(read)
+			if err != nil {
+				dialog.ShowError(err, s.mainWindow)
+				return
+			}
+			p, err := importCleanupProfile(data)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid cleanup profile: %v", err), s.mainWindow)
+				return
+			}
+			profiles, _ = loadCleanupProfiles()
+			profileSelect.Options = profileNames()
+			profileSelect.SetSelected(p.Name)
+			dialog.ShowInformation("Profile Imported", fmt.Sprintf("Imported %s with %d target(s).", p.Name, len(p.Targets)), s.mainWindow)
+		}, s.mainWindow)
+	})
 
-		var dirs []string
-		if chkDownloads.Checked {
-			dirs = append(dirs, RECOMM_DIR_DOWNLOADS)
+	// Filters applied to the scan results
+	scFilter := s.newFilterForm(FileFilter{MinSize: int64(s.settings.MinSizeMB * 1048576)})
+	filterAccordion := widget.NewAccordion(widget.NewAccordionItem("Filters", scFilter.content()))
+
+	readFilter := func() (FileFilter, bool) {
+		f, err := scFilter.read()
+		if err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return f, false
+		}
+		return f, true
+	}
+
+	// Category view with per-category totals
+	s.scCategorySel = widget.NewSelect(nil, func(val string) {
+		s.mu.Lock()
+		s.scCategory = ""
+		for _, c := range fileCategories {
+			if strings.HasPrefix(val, c+" (") {
+				s.scCategory = c
+			}
 		}
-		if chkTemp.Checked {
-			dirs = append(dirs, RECOMM_DIR_TEMP)
+		s.mu.Unlock()
+		s.refreshLargeFiles()
+	})
+	s.updateCategorySelect()
+
+	// Treemap of the scanned directories; tapping a folder lists its largest files
+	s.scScopeLabel = widget.NewLabel("")
+	s.scTreemap = newTreemap()
+	s.scTreemap.OnNodeSelected = func(node *DirNode) {
+		s.setScope(node)
+		s.refreshLargeFiles()
+	}
+	upBtn := s.commandButton(toolSpaceCleaner, "Up", nil, func() {
+		s.scTreemap.up()
+	})
+	s.setScope(nil)
+
+	selectAllRecs := s.commandButton(toolSpaceCleaner, "Select All Targets", nil, func() {
+		for i, chk := range targetChecks {
+			if !chk.Disabled() && targets[i].Safety != safetyCaution {
+				chk.SetChecked(true)
+			}
 		}
-		if chkPrograms.Checked {
-			dirs = append(dirs, RECOMM_DIR_PROGRAMS)
+	})
+
+	scanRecsBtn := s.commandButton(toolSpaceCleaner, "Scan", shortcutScan, func() {
+		s.mu.Lock()
+		s.largeFileItems = []*LargeFileItem{}
+		s.mu.Unlock()
+		s.refreshLargeFiles()
+
+		filter, ok := readFilter()
+		if !ok {
+			return
 		}
-		if chkAppData.Checked {
-			dirs = append(dirs, RECOMM_DIR_APPDATA)
+		var toScan []scanTarget
+		for i, chk := range targetChecks {
+			if chk.Checked {
+				toScan = append(toScan, scanTarget{
+					dir:     expandPathTemplate(targets[i].Path),
+					filter:  filter.withTargetRules(targets[i]),
+					caution: targets[i].Safety == safetyCaution,
+				})
+			}
 		}
-		if len(dirs) == 0 {
-			dialog.ShowInformation("No Directory", "No recommended directories selected.", s.mainWindow)
+		if len(toScan) == 0 {
+			dialog.ShowInformation("No Directory", "No cleanup targets selected.", s.mainWindow)
 			return
 		}
-		s.showScanningLargeFiles(dirs, spaceContainer)
+		s.showScanningLargeFiles(toScan)
 	})
 
-	manualScanBtn := widget.NewButton("Manual Selection", func() {
+	manualScanBtn := s.commandButton(toolSpaceCleaner, "Manual Selection", nil, func() {
+		filter, ok := readFilter()
+		if !ok {
+			return
+		}
 		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err != nil || uri == nil {
 				return
@@ -586,188 +1115,222 @@ func (s *FileScanner) setupSpaceCleanerUI() fyne.CanvasObject {
 			if strings.HasPrefix(fullURI, "file://") {
 				fullURI = strings.TrimPrefix(fullURI, "file://")
 			}
-			spaceContainer.Objects = nil
+			s.mu.Lock()
 			s.largeFileItems = []*LargeFileItem{}
-			s.scCurrentPage = 0
-			s.showScanningLargeFiles([]string{fullURI}, spaceContainer)
+			s.mu.Unlock()
+			s.refreshLargeFiles()
+			s.showScanningLargeFiles([]scanTarget{{dir: fullURI, filter: filter}})
 		}, s.mainWindow)
 	})
 
-	purgeBtn := widget.NewButton("Purge Selected", func() {
-		if len(s.largeFileItems) == 0 {
-			dialog.ShowInformation("No Files", "Please scan first, then select files.", s.mainWindow)
-			return
-		}
-		start := s.scCurrentPage * s.scPageSize
-		end := start + s.scPageSize
-		if end > len(s.largeFileItems) {
-			end = len(s.largeFileItems)
+	// dropPurged removes deleted files from the list
+	dropPurged := func(deleted []string) {
+		// remove them from largeFileItems
+		s.mu.Lock()
+		var newList []*LargeFileItem
+		for _, lf := range s.largeFileItems {
+			keep := true
+			for _, d := range deleted {
+				if lf.filePath == d {
+					keep = false
+					break
+				}
+			}
+			if keep {
+				newList = append(newList, lf)
+			}
 		}
+		s.largeFileItems = newList
+		s.mu.Unlock()
+		s.updateCategorySelect()
+		s.refreshLargeFiles()
+	}
+
+	scDryRunCheck := widget.NewCheck("Dry run", nil)
 
+	purgeBtn := s.commandButton(toolSpaceCleaner, "Purge Selected", shortcutDelete, func() {
+		s.mu.Lock()
+		scanned := len(s.largeFileItems)
 		var toDelete []string
-		for i := start; i < end; i++ {
-			lf := s.largeFileItems[i]
-			if lf.check != nil && lf.check.Checked {
+		for _, lf := range s.scVisible {
+			if lf.selected {
 				toDelete = append(toDelete, lf.filePath)
 			}
 		}
+		s.mu.Unlock()
+		if scanned == 0 {
+			dialog.ShowInformation("No Files", "Please scan first, then select files.", s.mainWindow)
+			return
+		}
 		if len(toDelete) == 0 {
 			dialog.ShowInformation("No Files Selected", "Select at least one file.", s.mainWindow)
 			return
 		}
-		dialog.ShowConfirm("Confirm Purge", fmt.Sprintf("Purge %d file(s)?", len(toDelete)), func(c bool) {
-			if !c {
+		s.confirmCaution(toDelete, func() {
+			if scDryRunCheck.Checked {
+				s.showPlanReview(s.spaceCleanerPlan(toDelete), dropPurged)
 				return
 			}
-			var errs []string
-			var purged int
-			for _, fp := range toDelete {
-				err := os.Remove(fp)
-				if err != nil {
-					errs = append(errs, fmt.Sprintf("Failed to purge %s: %v", fp, err))
-				} else {
-					purged++
-					s.addDeletionRecord(fp, "Space Cleaner")
-				}
-			}
-			if len(errs) > 0 {
-				dialog.ShowError(fmt.Errorf(strings.Join(errs, "\n")), s.mainWindow)
-			}
-			dialog.ShowInformation("Purge Complete", fmt.Sprintf("Purged %d file(s).", purged), s.mainWindow)
-			s.refreshDeletionTable()
-
-			// remove them from largeFileItems
-			var newList []*LargeFileItem
-			for _, lf := range s.largeFileItems {
-				keep := true
-				for _, d := range toDelete {
-					if lf.filePath == d {
-						keep = false
-						break
+			s.confirmGuarded("Confirm Purge", "Purge", toDelete, func(allowed []string) {
+				var errs []string
+				var purged []string
+				for _, fp := range allowed {
+					err := os.Remove(fp)
+					if err != nil {
+						errs = append(errs, fmt.Sprintf("Failed to purge %s: %v", fp, err))
+					} else {
+						purged = append(purged, fp)
+						s.addDeletionRecord(fp, "Space Cleaner")
 					}
 				}
-				if keep {
-					newList = append(newList, lf)
+				if len(errs) > 0 {
+					dialog.ShowError(fmt.Errorf(strings.Join(errs, "\n")), s.mainWindow)
 				}
-			}
-			s.largeFileItems = newList
-
-			// recalc pages
-			if len(s.largeFileItems) == 0 {
-				s.scTotalPages = 1
-			} else {
-				s.scTotalPages = (len(s.largeFileItems) + s.scPageSize - 1) / s.scPageSize
-			}
-			if s.scCurrentPage >= s.scTotalPages {
-				s.scCurrentPage = s.scTotalPages - 1
-			}
-			s.refreshLargeFiles(spaceContainer)
-		}, s.mainWindow)
+				dialog.ShowInformation("Purge Complete", fmt.Sprintf("Purged %d file(s).", len(purged)), s.mainWindow)
+				s.refreshDeletionTable()
+				dropPurged(purged)
+			})
+		})
 	})
 
-	// Pagination row for space cleaner
-	s.scPageLabel = widget.NewLabel("")
-	s.scPrevBtn = widget.NewButton("<<< Prev Page", func() {
-		if s.scCurrentPage > 0 {
-			s.scCurrentPage--
-			s.refreshLargeFiles(spaceContainer)
-		}
+	selectAllFiles := s.commandButton(toolSpaceCleaner, "Select All", shortcutSelectAll, func() {
+		s.scList.setAll(true)
 	})
-	s.scNextBtn = widget.NewButton("Next Page >>>", func() {
-		if s.scCurrentPage < s.scTotalPages-1 {
-			s.scCurrentPage++
-			s.refreshLargeFiles(spaceContainer)
-		}
+	deselectAllFiles := s.commandButton(toolSpaceCleaner, "Deselect All", shortcutSelectNone, func() {
+		s.scList.setAll(false)
 	})
-	scPagingBox := container.NewHBox(s.scPrevBtn, s.scPageLabel, s.scNextBtn)
 
 	topBox := container.NewVBox(
-		lbl,
-		chkDownloads,
-		chkTemp,
-		chkPrograms,
-		chkAppData,
+		container.NewHBox(lbl, profileSelect, importProfileBtn),
+		targetsBox,
 		selectAllRecs,
+		filterAccordion,
 		container.NewHBox(scanRecsBtn, manualScanBtn),
-		scPagingBox,
+		container.NewHBox(widget.NewLabel("Category:"), s.scCategorySel, selectAllFiles, deselectAllFiles),
+		s.scCountLabel,
 	)
 
+	protectedBtn := s.commandButton(toolSpaceCleaner, "Protected Paths", nil, func() {
+		s.showProtectedPathsDialog()
+	})
+
+	s.scWatchCheck = widget.NewCheck("Watch for changes", s.watchLargeFiles)
+
 	bottomBox := container.NewHBox(
 		purgeBtn,
+		scDryRunCheck,
+		s.scWatchCheck,
 		layout.NewSpacer(),
+		protectedBtn,
 	)
 
-	s.scTotalPages = 0
-	s.updateSpaceCleanerPageLabel()
+	s.refreshLargeFiles()
+
+	treemapBox := container.NewBorder(
+		container.NewHBox(upBtn, s.scScopeLabel),
+		nil, nil, nil,
+		s.scTreemap,
+	)
+	center := container.NewHSplit(s.scList.List, treemapBox)
+	center.Offset = 0.55
 
 	return container.NewBorder(
 		topBox,
 		bottomBox,
 		nil,
 		nil,
-		scroll,
+		center,
 	)
 }
 
-func (s *FileScanner) updateSpaceCleanerPageLabel() {
-	if s.scTotalPages == 0 {
-		s.scPageLabel.SetText("Page 0 of 0")
-		s.scPrevBtn.Disable()
-		s.scNextBtn.Disable()
-		return
+// updateSpaceCleanerCount shows how many files are listed and selected
+func (s *FileScanner) updateSpaceCleanerCount() {
+	var n int
+	var size int64
+	s.mu.Lock()
+	shown := len(s.scVisible)
+	for _, lf := range s.scVisible {
+		if lf.selected {
+			n++
+			size += lf.size
+		}
 	}
-	s.scPageLabel.SetText(fmt.Sprintf("Page %d of %d", s.scCurrentPage+1, s.scTotalPages))
-
-	s.scPrevBtn.Disable()
-	s.scNextBtn.Disable()
+	s.mu.Unlock()
+	s.scCountLabel.SetText(fmt.Sprintf("%d file(s) shown, %d selected (%s)", shown, n, formatSize(size)))
+}
 
-	if s.scCurrentPage > 0 {
-		s.scPrevBtn.Enable()
+// visibleLargeFiles returns the scan results in the selected category and treemap folder.
+// The caller holds s.mu.
+func (s *FileScanner) visibleLargeFiles() []*LargeFileItem {
+	if s.scCategory == "" && s.scScope == "" {
+		return s.largeFileItems
 	}
-	if s.scCurrentPage < s.scTotalPages-1 {
-		s.scNextBtn.Enable()
+	var res []*LargeFileItem
+	for _, lf := range s.largeFileItems {
+		if s.scCategory != "" && lf.category != s.scCategory {
+			continue
+		}
+		if s.scScope != "" && !strings.HasPrefix(lf.filePath, s.scScope+string(filepath.Separator)) {
+			continue
+		}
+		res = append(res, lf)
 	}
+	return res
 }
 
-// refreshLargeFiles shows only the current page
-func (s *FileScanner) refreshLargeFiles(containerToFill *fyne.Container) {
-	containerToFill.Objects = nil
-
-	if len(s.largeFileItems) == 0 {
-		containerToFill.Add(widget.NewLabel("No large files found."))
-		containerToFill.Refresh()
-		s.scTotalPages = 1
-		s.scCurrentPage = 0
-		s.updateSpaceCleanerPageLabel()
-		return
-	}
-
-	s.scTotalPages = (len(s.largeFileItems) + s.scPageSize - 1) / s.scPageSize
-	if s.scCurrentPage >= s.scTotalPages {
-		s.scCurrentPage = s.scTotalPages - 1
-	}
-	if s.scCurrentPage < 0 {
-		s.scCurrentPage = 0
+// setScope limits the file list to the folder shown in the treemap
+func (s *FileScanner) setScope(node *DirNode) {
+	scope := ""
+	text := "Showing: all scanned directories"
+	if node != nil && node.Path != "" {
+		scope = node.Path
+		text = fmt.Sprintf("Showing: %s (%s)", node.Path, formatSize(node.Size))
 	}
+	s.mu.Lock()
+	s.scScope = scope
+	s.mu.Unlock()
+	s.scScopeLabel.SetText(text)
+}
 
-	start := s.scCurrentPage * s.scPageSize
-	end := start + s.scPageSize
-	if end > len(s.largeFileItems) {
-		end = len(s.largeFileItems)
+// updateCategorySelect lists every category with its file count and total size
+func (s *FileScanner) updateCategorySelect() {
+	s.mu.Lock()
+	totals := categoryTotals(s.largeFileItems)
+	var all int64
+	for _, lf := range s.largeFileItems {
+		all += lf.size
 	}
-	for i := start; i < end; i++ {
-		lf := s.largeFileItems[i]
-		if lf.check == nil {
-			lf.check = widget.NewCheck(
-				fmt.Sprintf("%s (%.2f MB)", lf.filePath, float64(lf.size)/1048576),
-				func(bool) {},
-			)
+	allOpt := fmt.Sprintf("All (%d files, %s)", len(s.largeFileItems), formatSize(all))
+	options := []string{allOpt}
+	selected := allOpt
+	for _, c := range fileCategories {
+		t, ok := totals[c]
+		if !ok {
+			continue
+		}
+		opt := fmt.Sprintf("%s (%d files, %s)", c, t.Count, formatSize(t.Size))
+		options = append(options, opt)
+		if c == s.scCategory {
+			selected = opt
 		}
-		containerToFill.Add(lf.check)
 	}
-	containerToFill.Refresh()
+	if selected == allOpt {
+		s.scCategory = ""
+	}
+	s.mu.Unlock()
+	s.scCategorySel.Options = options
+	s.scCategorySel.Selected = selected
+	s.scCategorySel.Refresh()
+}
 
-	s.updateSpaceCleanerPageLabel()
+// refreshLargeFiles lists the files in the selected category and folder
+func (s *FileScanner) refreshLargeFiles() {
+	s.mu.Lock()
+	s.scVisible = s.visibleLargeFiles()
+	s.mu.Unlock()
+	if s.scList != nil {
+		s.scList.Refresh()
+	}
 }
 
 // ---------------------------------------------------------------------
@@ -776,8 +1339,11 @@ func (s *FileScanner) refreshLargeFiles(containerToFill *fyne.Container) {
 
 func (s *FileScanner) setupHistoryUI() fyne.CanvasObject {
 	// We'll place "Save History" & "Clear" at the top, then the table in the center filling the window
-	saveBtn := widget.NewButton("Save History", func() {
-		if len(s.deletionRecords) == 0 {
+	saveBtn := s.commandButton(toolHistory, "Save History", nil, func() {
+		s.mu.Lock()
+		records := append([]DeletionRecord(nil), s.deletionRecords...)
+		s.mu.Unlock()
+		if len(records) == 0 {
 			dialog.ShowInformation("No History", "No deleted files.", s.mainWindow)
 			return
 		}
@@ -787,22 +1353,32 @@ func (s *FileScanner) setupHistoryUI() fyne.CanvasObject {
 			}
 			defer write.Close()
 			var sb strings.Builder
-			for _, r := range s.deletionRecords {
-				sb.WriteString(fmt.Sprintf("%s\t%s\t%s\n", r.Timestamp, r.FilePath, r.Method))
+			for _, r := range records {
+				sb.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n", r.Timestamp, r.FilePath, r.Method, r.PlanID))
 			}
 			write.Write([]byte(sb.String()))
 			dialog.ShowInformation("Saved", "Deletion history saved.", s.mainWindow)
 		}, s.mainWindow)
 	})
 
-	clearBtn := widget.NewButton("Clear History", func() {
+	clearBtn := s.commandButton(toolHistory, "Clear History", nil, func() {
+		s.mu.Lock()
 		s.deletionRecords = nil
+		s.mu.Unlock()
+		if err := os.WriteFile(deletionHistoryFilePath, nil, 0644); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+		}
 		s.refreshDeletionTable()
 	})
 
+	openPlanBtn := s.commandButton(toolHistory, "Open Plan", nil, func() {
+		s.openPlanDialog()
+	})
+
 	topBar := container.NewHBox(
 		saveBtn,
 		clearBtn,
+		openPlanBtn,
 		layout.NewSpacer(),
 	)
 
@@ -823,8 +1399,10 @@ func (s *FileScanner) setupHistoryUI() fyne.CanvasObject {
 func (s *FileScanner) makeHistoryTable() fyne.CanvasObject {
 	table := widget.NewTable(
 		func() (int, int) {
-			// #rows = #records + 1 (header), #cols=3
-			return len(s.deletionRecords) + 1, 3
+			// #rows = #records + 1 (header), #cols=4
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.deletionRecords) + 1, 4
 		},
 		func() fyne.CanvasObject {
 			return widget.NewLabel("")
@@ -844,15 +1422,24 @@ func (s *FileScanner) makeHistoryTable() fyne.CanvasObject {
 				case 2:
 					label.SetText("Method")
 					label.TextStyle = fyne.TextStyle{Bold: true}
+				case 3:
+					label.SetText("Plan")
+					label.TextStyle = fyne.TextStyle{Bold: true}
 				}
 				return
 			}
 			recIndex := row - 1
-			if recIndex < 0 || recIndex >= len(s.deletionRecords) {
+			s.mu.Lock()
+			var rec DeletionRecord
+			ok := recIndex >= 0 && recIndex < len(s.deletionRecords)
+			if ok {
+				rec = s.deletionRecords[recIndex]
+			}
+			s.mu.Unlock()
+			if !ok {
 				label.SetText("")
 				return
 			}
-			rec := s.deletionRecords[recIndex]
 			label.TextStyle = fyne.TextStyle{}
 			switch col {
 			case 0:
@@ -861,12 +1448,15 @@ func (s *FileScanner) makeHistoryTable() fyne.CanvasObject {
 				label.SetText(rec.FilePath)
 			case 2:
 				label.SetText(rec.Method)
+			case 3:
+				label.SetText(rec.PlanID)
 			}
 		},
 	)
 	table.SetColumnWidth(0, 180)
 	table.SetColumnWidth(1, 550)
 	table.SetColumnWidth(2, 150)
+	table.SetColumnWidth(3, 170)
 
 	return table
 }
@@ -876,9 +1466,9 @@ func (s *FileScanner) refreshDeletionTable() {
 	if s.historyRoot == nil {
 		return
 	}
-	newUI := s.setupHistoryUI()
-	s.historyRoot = newUI
-	if s.split != nil {
+	old := s.historyRoot
+	s.historyRoot = s.setupHistoryUI()
+	if s.split != nil && s.split.Trailing == old {
 		// If we are currently on the "Deletion History" tab, re-show it
 		s.switchRightContent(s.historyRoot)
 	}
@@ -889,56 +1479,249 @@ func (s *FileScanner) refreshDeletionTable() {
 // ---------------------------------------------------------------------
 
 func (s *FileScanner) addDeletionRecord(filePath, method string) {
+	s.addDeletionRecordWithPlan(filePath, method, "")
+}
+
+// addDeletionRecordWithPlan links the record to the deletion plan that removed the file
+func (s *FileScanner) addDeletionRecordWithPlan(filePath, method, planID string) {
 	ts := time.Now().Format("2006-01-02 15:04:05")
-	s.deletionRecords = append(s.deletionRecords, DeletionRecord{
+	rec := DeletionRecord{
 		Timestamp: ts,
 		FilePath:  filePath,
 		Method:    method,
-	})
+		PlanID:    planID,
+	}
+	s.mu.Lock()
+	s.deletionRecords = append(s.deletionRecords, rec)
+	s.mu.Unlock()
+	if err := appendDeletionHistory(rec); err != nil {
+		fmt.Println("Error writing deletion history:", err)
+	}
+}
+
+// appendDeletionHistory appends records to the history file. Scheduled jobs
+// running in daemon mode write to the same file, so it is never rewritten.
+func appendDeletionHistory(recs ...DeletionRecord) error {
+	f, err := os.OpenFile(deletionHistoryFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *FileScanner) showScanningDuplicates(dirPath, extFilter string) {
+func loadDeletionHistory() []DeletionRecord {
+	data, err := os.ReadFile(deletionHistoryFilePath)
+	if err != nil {
+		return []DeletionRecord{}
+	}
+	records := []DeletionRecord{}
+	for _, line := range strings.Split(string(data), "\n") {
+		var r DeletionRecord
+		if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &r) != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// showScanningDuplicates finds files with the same content. With archives
+// set, files inside zip and tar archives are compared as well.
+func (s *FileScanner) showScanningDuplicates(roots []ScanRoot, filter FileFilter, archives bool) {
 	pb := widget.NewProgressBarInfinite()
 	lbl := widget.NewLabel("Scanning for duplicates...")
 	vbox := container.NewVBox(lbl, pb)
 
 	dlg := dialog.NewCustom("Please Wait", "Cancel", vbox, s.mainWindow)
-	dlg.Show()
+	scan := s.startScan(dlg)
+	algo := s.settings.HashAlgorithm
+	hasher, _ := lookupHashAlgorithm(algo)
+	verify := s.settings.VerifyHashes && !hasher.secure
 
 	go func() {
-		files, e := s.scanDirectory(dirPath, extFilter)
+		walkFilter := filter
+		if archives {
+			walkFilter = filter.folderRules() // archives are opened whatever the filter says
+		}
+		files, skippedPaths, e := s.scanRoots(roots, walkFilter)
 		if e != nil {
-			dlg.Hide()
-			dialog.ShowError(e, s.mainWindow)
+			s.postUI(func() {
+				if !s.finishScan(scan) {
+					return
+				}
+				dlg.Hide()
+				dialog.ShowError(e, s.mainWindow)
+			})
 			return
 		}
 		// find duplicates
-		m := s.findDuplicates(files)
-		// flatten them into s.allFileItems
+		var m, h map[string][]string
+		entrySizes := make(map[string]int64)
+		entrySHA := make(map[string]string)
+		entryArchives := make(map[string]string)
+		compared := files
+		if archives {
+			now := time.Now()
+			var disk, archiveFiles []string
+			for _, f := range files {
+				if archiveFormat(f) != "" {
+					archiveFiles = append(archiveFiles, f)
+				}
+				if st, err := os.Stat(f); err == nil && filter.matches(f, st, now) {
+					disk = append(disk, f)
+				}
+			}
+			compared = disk
+			h = s.hashFiles(disk, algo)
+			for i, a := range archiveFiles {
+				if !s.scanRunning(scan) {
+					return
+				}
+				text := fmt.Sprintf("Reading archives... %d of %d", i+1, len(archiveFiles))
+				s.postUI(func() { lbl.SetText(text) })
+				entries, err := hashArchive(a, algo, verify)
+				if err != nil {
+					// entries read before the error are still compared
+					skippedPaths = append(skippedPaths, SkippedPath{Path: a, Reason: "archive only partly read: " + err.Error()})
+				}
+				for _, en := range entries {
+					if filter.sizeOK(en.Size) && filter.matchesPath(en.Path) {
+						key := fmt.Sprintf("%s-%d", en.Hash, en.Size)
+						h[key] = append(h[key], en.Path)
+						entrySizes[en.Path] = en.Size
+						entrySHA[en.Path] = en.SHA
+						entryArchives[en.Path] = a
+					}
+				}
+			}
+			m = duplicateGroups(h)
+		} else {
+			h = s.hashFiles(files, algo)
+			m = duplicateGroups(h)
+		}
+		if verify {
+			s.postUI(func() { lbl.SetText("Verifying with SHA-256...") })
+			m = s.verifyGroups(m, entrySHA)
+		}
+		s.saveHashCache()
+		// empty files have nothing to compare, so they are counted instead
+		var empty int
+		for _, f := range compared {
+			if fileSize(f) == 0 {
+				empty++
+			}
+		}
+		// flatten them into the list items
 		var items []*FileItem
 		for _, group := range m {
 			if len(group) > 1 {
 				for _, gpath := range group {
-					items = append(items, &FileItem{filePath: gpath})
+					size, ok := entrySizes[gpath]
+					if !ok {
+						size = fileSize(gpath)
+					}
+					items = append(items, &FileItem{filePath: gpath, size: size, archive: entryArchives[gpath]})
 				}
 			}
 		}
-		s.allFileItems = items
-		dlg.Hide()
+		items, m = applyRoles(roots, items, m)
+		stampModTimes(items)
+		index := newHashIndex(h, entryArchives, algo, verify, filter)
 
-		if len(s.allFileItems) == 0 {
-			dialog.ShowInformation("No Duplicates", "No duplicate files found.", s.mainWindow)
-		} else {
-			msg := fmt.Sprintf("Found %d total duplicate files.", len(s.allFileItems))
-			dialog.ShowInformation("Scan Complete", msg, s.mainWindow)
-		}
-		s.dfCurrentPage = 0
-		s.refreshDuplicates()
+		s.postUI(func() {
+			if !s.finishScan(scan) {
+				return
+			}
+			s.mu.Lock()
+			s.allFileItems = items
+			s.allDuplicates = m
+			s.dfAlgorithm, s.dfVerified = algo, verify
+			s.dfIndex = index
+			s.mu.Unlock()
+			dlg.Hide()
+
+			skipped := ""
+			if empty > 0 {
+				skipped = fmt.Sprintf("\n%d empty file(s) were not compared.", empty)
+			}
+			if len(items) == 0 {
+				s.showScanResult("No Duplicates", "No duplicate files found."+skipped, skippedPaths)
+			} else {
+				msg := fmt.Sprintf("Found %d total duplicate files.", len(items)) + skipped
+				s.showScanResult("Scan Complete", msg, skippedPaths)
+			}
+			s.refreshDuplicates()
+			s.watchDuplicates(s.dfWatchCheck.Checked)
+		})
 	}()
 }
 
-func loadPasswords() map[string]string {
-	file, err := os.Open(passwordFilePath)
+// startScan shows dlg as the progress dialog of a new scan, cancelling any
+// scan still running. Closing the dialog cancels the scan. The returned
+// number identifies the scan to finishScan.
+func (s *FileScanner) startScan(dlg dialog.Dialog) int {
+	s.cancelScan()
+	s.mu.Lock()
+	s.scanGen++
+	gen := s.scanGen
+	s.scanDlg = dlg
+	s.mu.Unlock()
+
+	dlg.SetOnClosed(func() {
+		s.mu.Lock()
+		if s.scanDlg == dlg {
+			s.scanDlg = nil
+			s.scanGen++
+		}
+		s.mu.Unlock()
+	})
+	dlg.Show()
+	return gen
+}
+
+// finishScan reports whether the scan's results should be shown, i.e. it
+// was not cancelled in the meantime.
+func (s *FileScanner) finishScan(gen int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.scanGen || s.scanDlg == nil {
+		return false
+	}
+	s.scanDlg = nil
+	return true
+}
+
+// scanRunning reports whether the scan has been neither finished nor
+// cancelled, so long scans can stop early.
+func (s *FileScanner) scanRunning(gen int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return gen == s.scanGen && s.scanDlg != nil
+}
+
+// cancelScan closes the running scan's dialog; its results are dropped.
+func (s *FileScanner) cancelScan() {
+	s.mu.Lock()
+	dlg := s.scanDlg
+	if dlg != nil {
+		s.scanDlg = nil
+		s.scanGen++
+	}
+	s.mu.Unlock()
+	if dlg != nil {
+		dlg.Hide()
+	}
+}
+
+func loadPasswords(path string) map[string]string {
+	file, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return make(map[string]string)
@@ -968,7 +1751,7 @@ func loadPasswords() map[string]string {
 	return passwords
 }
 
-func savePasswords(passwords map[string]string) {
+func savePasswords(path string, passwords map[string]string) {
 	encryptedPasswords := make(map[string]string)
 	for website, password := range passwords {
 		encrypted, err := encrypt(password)
@@ -979,7 +1762,7 @@ func savePasswords(passwords map[string]string) {
 		encryptedPasswords[website] = encrypted
 	}
 
-	file, err := os.Create(passwordFilePath)
+	file, err := os.Create(path)
 	if err != nil {
 		fmt.Println("Error creating file:", err)
 		os.Exit(1)
@@ -993,43 +1776,72 @@ func savePasswords(passwords map[string]string) {
 	}
 }
 
-func (s *FileScanner) showScanningLargeFiles(dirs []string, containerToFill *fyne.Container) {
+func (s *FileScanner) showScanningLargeFiles(targets []scanTarget) {
 	pb := widget.NewProgressBarInfinite()
 	lbl := widget.NewLabel("Scanning for large files...")
 	vbox := container.NewVBox(lbl, pb)
 
 	dlg := dialog.NewCustom("Please Wait", "Cancel", vbox, s.mainWindow)
-	dlg.Show()
+	scan := s.startScan(dlg)
+	s.watchLargeFiles(false)
 
 	go func() {
-		var allFiles []string
-		for _, d := range dirs {
-			fs, _ := s.scanDirectory(d, "")
-			allFiles = append(allFiles, fs...)
-		}
-		sort.Slice(allFiles, func(i, j int) bool {
-			si, _ := os.Stat(allFiles[i])
-			sj, _ := os.Stat(allFiles[j])
-			if si == nil || sj == nil {
-				return false
+		var dirs []string
+		for _, t := range targets {
+			dirs = append(dirs, t.dir)
+		}
+		tree := newDirTree(dirs)
+		now := time.Now()
+		seen := make(map[string]bool) // targets may be nested inside each other
+		var items []*LargeFileItem
+		var skipped []SkippedPath
+		for _, t := range targets {
+			fs, sk, err := s.scanDirectory(t.dir, t.filter.folderRules())
+			if err != nil {
+				sk = append(sk, skippedPath(t.dir, err))
+			}
+			skipped = append(skipped, sk...)
+			for _, f := range fs {
+				if seen[f] {
+					continue
+				}
+				seen[f] = true
+				st, ee := os.Stat(f)
+				if ee != nil || st.IsDir() {
+					continue
+				}
+				tree.addFile(t.dir, f, st.Size())
+				if t.filter.matches(f, st, now) {
+					lf := &LargeFileItem{filePath: f, size: st.Size(), category: fileCategory(f)}
+					items = append(items, lf)
+				}
 			}
-			return si.Size() > sj.Size()
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].size > items[j].size
 		})
 
-		for _, f := range allFiles {
-			st, ee := os.Stat(f)
-			if ee == nil && !st.IsDir() {
-				lf := &LargeFileItem{filePath: f, size: st.Size()}
-				s.largeFileItems = append(s.largeFileItems, lf)
+		s.postUI(func() {
+			if !s.finishScan(scan) {
+				return
 			}
-		}
-		dlg.Hide()
+			s.mu.Lock()
+			s.largeFileItems = items
+			s.scTargets = targets
+			s.mu.Unlock()
+			dlg.Hide()
 
-		if len(s.largeFileItems) == 0 {
-			dialog.ShowInformation("No Files", "No large files found.", s.mainWindow)
-		}
-		s.scCurrentPage = 0
-		s.refreshLargeFiles(containerToFill)
+			if len(items) == 0 {
+				s.showScanResult("No Files", "No large files found.", skipped)
+			} else if len(skipped) > 0 {
+				s.showScanResult("Scan Complete", fmt.Sprintf("Found %d file(s).", len(items)), skipped)
+			}
+			s.setScope(tree)
+			s.scTreemap.setRoot(tree)
+			s.updateCategorySelect()
+			s.refreshLargeFiles()
+			s.watchLargeFiles(s.scWatchCheck.Checked)
+		})
 	}()
 }
 
@@ -1082,45 +1894,45 @@ func decrypt(encryptedText string) (string, error) {
 	return string(plaintext), nil
 }
 
-func (s *FileScanner) scanDirectory(dirPath, extFilter string) ([]string, error) {
+// scanDirectory lists the files under dirPath that pass filter. Folders the
+// filter excludes are not entered; dirPath itself always is. Paths that
+// cannot be read are returned as skipped instead of ending the scan.
+func (s *FileScanner) scanDirectory(dirPath string, filter FileFilter) ([]string, []SkippedPath, error) {
 	var files []string
-	filterSet := make(map[string]bool)
-
-	if extFilter != "" {
-		parts := strings.Split(extFilter, ",")
-		for _, part := range parts {
-			trim := strings.ToLower(strings.TrimSpace(part))
-			if trim != "" {
-				filterSet[trim] = true
+	now := time.Now()
+	skipped, err := walkTree(dirPath, s.walkOptions(), func(p string, info os.FileInfo) error {
+		if info.IsDir() {
+			if p != dirPath && filter.skipDir(p, info) {
+				return filepath.SkipDir
 			}
+			return nil
 		}
-	}
-
-	var mu sync.Mutex
-	err := filepath.Walk(dirPath, func(p string, info os.FileInfo, wErr error) error {
-		if wErr != nil {
-			return wErr
-		}
-		if !info.IsDir() {
-			if len(filterSet) > 0 {
-				ext := strings.ToLower(filepath.Ext(p))
-				if !filterSet[ext] {
-					return nil
-				}
-			}
-			mu.Lock()
+		if filter.matches(p, info, now) {
 			files = append(files, p)
-			mu.Unlock()
 		}
 		return nil
 	})
-	return files, err
+	return files, skipped, err
 }
 
-func (s *FileScanner) findDuplicates(fileList []string) map[string][]string {
+// parseExtFilter turns a comma-separated extension list into a list.
+func parseExtFilter(extFilter string) []string {
+	var exts []string
+	for _, part := range strings.Split(extFilter, ",") {
+		trim := strings.ToLower(strings.TrimSpace(part))
+		if trim != "" && !contains(exts, trim) {
+			exts = append(exts, trim)
+		}
+	}
+	return exts
+}
+
+// hashFiles groups files by content hash and size, including files that
+// have no copy; duplicateGroups keeps only the groups.
+func (s *FileScanner) hashFiles(fileList []string, algo string) map[string][]string {
 	h := make(map[string][]string)
 	for _, fp := range fileList {
-		hashStr, e := s.generateHash(fp)
+		hashStr, e := s.generateHash(fp, algo)
 		if e != nil {
 			continue
 		}
@@ -1131,7 +1943,11 @@ func (s *FileScanner) findDuplicates(fileList []string) map[string][]string {
 		key := fmt.Sprintf("%s-%d", hashStr, st.Size())
 		h[key] = append(h[key], fp)
 	}
-	// only keep groups of size > 1
+	return h
+}
+
+// duplicateGroups keeps the groups of h with more than one file.
+func duplicateGroups(h map[string][]string) map[string][]string {
 	res := make(map[string][]string)
 	for k, group := range h {
 		if len(group) > 1 {
@@ -1141,7 +1957,13 @@ func (s *FileScanner) findDuplicates(fileList []string) map[string][]string {
 	return res
 }
 
-func (s *FileScanner) generateHash(filePath string) (string, error) {
+// generateHash returns the hex hash of a file's content with the named
+// algorithm, reusing the hash cache while the file is unchanged.
+func (s *FileScanner) generateHash(filePath, algo string) (string, error) {
+	hasher, e := lookupHashAlgorithm(algo)
+	if e != nil {
+		return "", e
+	}
 	f, e := os.Open(filePath)
 	if e != nil {
 		return "", e
@@ -1152,13 +1974,18 @@ func (s *FileScanner) generateHash(filePath string) (string, error) {
 	if e2 != nil || st.Size() == 0 {
 		return "", fmt.Errorf("file is unreadable or empty")
 	}
+	if sum, ok := s.hashCache().lookup(filePath, st, algo); ok {
+		return sum, nil
+	}
 
-	h := sha256.New()
+	h := hasher.newHash()
 	_, e2 = io.Copy(h, f)
 	if e2 != nil {
 		return "", e2
 	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	sum := hex.EncodeToString(h.Sum(nil))
+	s.hashCache().store(filePath, st, algo, sum)
+	return sum, nil
 }
 
 func (s *FileScanner) summarize(d map[string][]string) (int, int64) {
@@ -1182,7 +2009,7 @@ func (s *FileScanner) summarize(d map[string][]string) (int, int64) {
 }
 
 func (s *FileScanner) setupPasswordManagerUI() fyne.CanvasObject {
-	passwords := loadPasswords()
+	passwords := loadPasswords(s.settings.VaultPath)
 
 	passwordList := widget.NewList(
 		func() int {
@@ -1203,7 +2030,7 @@ func (s *FileScanner) setupPasswordManagerUI() fyne.CanvasObject {
 		},
 	)
 
-	addPasswordBtn := widget.NewButton("Add Password", func() {
+	addPasswordBtn := s.commandButton(toolPasswordManager, "Add Password", nil, func() {
 		websiteEntry := widget.NewEntry()
 		passwordEntry := widget.NewPasswordEntry()
 
@@ -1224,7 +2051,7 @@ func (s *FileScanner) setupPasswordManagerUI() fyne.CanvasObject {
 				passwords[website] = password
 
 				// Save to JSON file
-				savePasswords(passwords)
+				savePasswords(s.settings.VaultPath, passwords)
 
 				// Refresh password list
 				passwordList.Refresh()
@@ -1234,11 +2061,11 @@ func (s *FileScanner) setupPasswordManagerUI() fyne.CanvasObject {
 		}, s.mainWindow)
 	})
 
-	removePasswordBtn := widget.NewButton("Remove Password", func() {
+	removePasswordBtn := s.commandButton(toolPasswordManager, "Remove Password", nil, func() {
 		dialog.ShowEntryDialog("Remove Password", "Enter website to remove:", func(website string) {
 			if _, exists := passwords[website]; exists {
 				delete(passwords, website)
-				savePasswords(passwords)
+				savePasswords(s.settings.VaultPath, passwords)
 				passwordList.Refresh()
 			} else {
 				dialog.ShowInformation("Not Found", "No password found for "+website, s.mainWindow)
@@ -1246,7 +2073,7 @@ func (s *FileScanner) setupPasswordManagerUI() fyne.CanvasObject {
 		}, s.mainWindow)
 	})
 
-	viewPasswordBtn := widget.NewButton("View Password", func() {
+	viewPasswordBtn := s.commandButton(toolPasswordManager, "View Password", nil, func() {
 		dialog.ShowEntryDialog("View Password", "Enter website to view:", func(website string) {
 			if password, exists := passwords[website]; exists {
 				dialog.ShowInformation("Password", fmt.Sprintf("Password for %s: %s", website, password), s.mainWindow)
@@ -1311,10 +2138,17 @@ func (s *FileScanner) setupSystemInfoUI() fyne.CanvasObject {
 		}
 	}
 
-	// Combine all information into a single string
-	systemInfo := fmt.Sprintf("%s\n%s\n%s\n%s\n%s",
-		cpuDetails, memDetails, diskDetails, hostDetails, monitorDetails)
+	// Fetch temperatures, fans and battery state where the platform provides them
+	sensorInfo := sensorDetails(newSensorProvider())
 
-	// Display all information in a scrollable text widget
-	return container.NewScroll(widget.NewLabel(systemInfo))
+	// Combine all information into a single string
+	systemInfo := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+		cpuDetails, memDetails, diskDetails, hostDetails, monitorDetails, sensorInfo)
+
+	// Display all information in a scrollable text widget, next to the alert rules
+	return container.NewAppTabs(
+		container.NewTabItem("Overview", container.NewScroll(widget.NewLabel(systemInfo))),
+		container.NewTabItem("History", s.setupMetricsHistoryUI()),
+		container.NewTabItem("Alerts", s.setupAlertsUI()),
+	)
 }
@@ -0,0 +1,1217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf16"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	mediaGroupPrefix       = "media:" // allDuplicates keys of media groups
+	defaultMediaConfidence = 60       // Percent; pairs below this are not grouped
+	mediaDurationWindow    = 10 * time.Second
+	fpcalcLength           = "120" // Seconds of audio fpcalc fingerprints
+	maxMediaHeader         = 16 << 20
+	maxMediaDuration       = 1000 * time.Hour                 // longer durations come from corrupt headers
+	mediaAlgorithm         = "tags, duration and fingerprint" // as recorded in exports
+)
+
+var (
+	audioExtensions = []string{".mp3", ".m4a", ".aac", ".flac", ".wav", ".mka"}
+	videoExtensions = []string{".mp4", ".m4v", ".mov", ".mkv", ".webm"}
+)
+
+// MediaInfo is what we could learn about an audio or video file without
+// decoding it, plus an audio fingerprint where one could be computed.
+type MediaInfo struct {
+	Path     string
+	Video    bool
+	Title    string
+	Artist   string
+	Album    string
+	Duration time.Duration // 0 if unknown
+	Bitrate  int           // kbps, 0 if unknown
+
+	// Fingerprint is a sequence of 32-bit sub-fingerprints. Only
+	// fingerprints of the same kind can be compared.
+	Fingerprint     []uint32
+	FingerprintKind string // "chromaprint" (fpcalc) or "envelope" (built in, WAV only)
+}
+
+func isMediaFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return contains(audioExtensions, ext) || contains(videoExtensions, ext)
+}
+
+// readMediaInfo parses the tags and duration of path by its container.
+func readMediaInfo(path string) (*MediaInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var magic [12]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		return nil, fmt.Errorf("%s: too short", path)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	mi := &MediaInfo{Path: path, Video: contains(videoExtensions, strings.ToLower(filepath.Ext(path)))}
+	switch {
+	case bytes.Equal(magic[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		err = parseMatroska(f, st.Size(), mi)
+	case string(magic[4:8]) == "ftyp":
+		err = parseMP4(f, st.Size(), mi)
+	case string(magic[:4]) == "fLaC":
+		err = parseFLAC(f, mi)
+	case string(magic[:4]) == "RIFF" && string(magic[8:12]) == "WAVE":
+		err = parseWAV(f, mi, nil)
+	case string(magic[:3]) == "ID3" || magic[0] == 0xFF && magic[1]&0xE0 == 0xE0:
+		err = parseMP3(f, st.Size(), mi)
+	default:
+		err = fmt.Errorf("%s: unknown media format", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mi.Bitrate == 0 && mi.Duration > 0 {
+		mi.Bitrate = int(float64(st.Size()*8) / mi.Duration.Seconds() / 1000)
+	}
+	return mi, nil
+}
+
+// mediaDuration converts a duration read from a header, treating values
+// that cannot be right as unknown.
+func mediaDuration(seconds float64) time.Duration {
+	if !(seconds > 0) || seconds > maxMediaDuration.Seconds() {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ---------------------------------------------------------------------
+//  1) MP3 (ID3 tags and MPEG frame headers)
+// ---------------------------------------------------------------------
+
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// id3Text decodes an ID3v2 text frame: an encoding byte, then the text.
+func id3Text(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	enc, b := b[0], b[1:]
+	var s string
+	switch enc {
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		order := binary.ByteOrder(binary.BigEndian)
+		if len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE {
+			order, b = binary.LittleEndian, b[2:]
+		} else if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+			b = b[2:]
+		}
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = order.Uint16(b[2*i:])
+		}
+		s = string(utf16.Decode(u))
+	case 3: // UTF-8
+		s = string(b)
+	default: // ISO-8859-1
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		s = string(r)
+	}
+	// multiple values are NUL separated; keep the first
+	if i := strings.IndexRune(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseID3v2 reads the tag at the start of r and returns its total length.
+func parseID3v2(r io.Reader, mi *MediaInfo) (int64, error) {
+	var hdr [10]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil || string(hdr[:3]) != "ID3" {
+		return 0, err
+	}
+	ver, flags, size := hdr[3], hdr[5], syncsafe(hdr[6:10])
+	total := int64(10 + size)
+	if flags&0x10 != 0 {
+		total += 10 // footer
+	}
+	if size > maxMediaHeader {
+		return total, nil
+	}
+	tag := make([]byte, size)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return total, err
+	}
+	if flags&0x40 != 0 && len(tag) >= 4 { // extended header
+		n := int(binary.BigEndian.Uint32(tag))
+		if ver == 4 {
+			n = syncsafe(tag)
+		} else {
+			n += 4
+		}
+		if n > len(tag) {
+			return total, nil
+		}
+		tag = tag[n:]
+	}
+
+	idLen, hdrLen := 4, 10
+	if ver == 2 {
+		idLen, hdrLen = 3, 6
+	}
+	for len(tag) >= hdrLen && tag[0] != 0 {
+		id := string(tag[:idLen])
+		var n int
+		switch ver {
+		case 2:
+			n = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 4:
+			n = syncsafe(tag[4:8])
+		default:
+			n = int(binary.BigEndian.Uint32(tag[4:8]))
+		}
+		if n < 0 || hdrLen+n > len(tag) {
+			break
+		}
+		body := tag[hdrLen : hdrLen+n]
+		switch id {
+		case "TIT2", "TT2":
+			mi.Title = id3Text(body)
+		case "TPE1", "TP1":
+			mi.Artist = id3Text(body)
+		case "TALB", "TAL":
+			mi.Album = id3Text(body)
+		case "TLEN", "TLE":
+			if ms, err := strconv.Atoi(id3Text(body)); err == nil && ms > 0 {
+				mi.Duration = mediaDuration(float64(ms) / 1000)
+			}
+		}
+		tag = tag[hdrLen+n:]
+	}
+	return total, nil
+}
+
+// parseID3v1 fills missing tags from the 128-byte tag at the end of the file.
+func parseID3v1(f io.ReaderAt, size int64, mi *MediaInfo) bool {
+	if size < 128 {
+		return false
+	}
+	var tag [128]byte
+	if _, err := f.ReadAt(tag[:], size-128); err != nil || string(tag[:3]) != "TAG" {
+		return false
+	}
+	field := func(b []byte) string {
+		return strings.TrimSpace(strings.TrimRight(string(b), "\x00"))
+	}
+	if mi.Title == "" {
+		mi.Title = field(tag[3:33])
+	}
+	if mi.Artist == "" {
+		mi.Artist = field(tag[33:63])
+	}
+	if mi.Album == "" {
+		mi.Album = field(tag[63:93])
+	}
+	return true
+}
+
+var (
+	mp3Bitrates = [2][16]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}, // MPEG-1 Layer III
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},     // MPEG-2/2.5 Layer III
+	}
+	mp3SampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+func parseMP3(f *os.File, size int64, mi *MediaInfo) error {
+	start, err := parseID3v2(f, mi)
+	if err != nil {
+		return err
+	}
+	end := size
+	if parseID3v1(f, size, mi) {
+		end -= 128
+	}
+	if mi.Duration > 0 {
+		return nil
+	}
+
+	// find the first Layer III frame header
+	buf := make([]byte, 64<<10)
+	n, _ := f.ReadAt(buf, start)
+	buf = buf[:n]
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		version, layer := (buf[i+1]>>3)&3, (buf[i+1]>>1)&3
+		brIdx, srIdx := buf[i+2]>>4, (buf[i+2]>>2)&3
+		if version == 1 || layer != 1 || brIdx == 0 || brIdx == 15 || srIdx == 3 {
+			continue
+		}
+		table, spf := 0, 1152
+		if version != 3 {
+			table, spf = 1, 576
+		}
+		bitrate := mp3Bitrates[table][brIdx]
+		rate := mp3SampleRates[version][srIdx]
+		mono := buf[i+3]>>6 == 3
+
+		// VBR files carry the frame count in a Xing/Info or VBRI header
+		side := 32
+		switch {
+		case version == 3 && mono:
+			side = 17
+		case version != 3 && !mono:
+			side = 17
+		case version != 3 && mono:
+			side = 9
+		}
+		var frames uint32
+		if x := i + 4 + side; x+12 <= len(buf) {
+			if tag := string(buf[x : x+4]); (tag == "Xing" || tag == "Info") && buf[x+7]&1 != 0 {
+				frames = binary.BigEndian.Uint32(buf[x+8:])
+			}
+		}
+		if v := i + 36; frames == 0 && v+18 <= len(buf) && string(buf[v:v+4]) == "VBRI" {
+			frames = binary.BigEndian.Uint32(buf[v+14:])
+		}
+		if frames > 0 {
+			mi.Duration = mediaDuration(float64(frames) * float64(spf) / float64(rate))
+		} else {
+			mi.Bitrate = bitrate
+			audio := end - start - int64(i)
+			mi.Duration = mediaDuration(float64(audio*8) / float64(bitrate*1000))
+		}
+		return nil
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------
+//  2) MP4 / QuickTime Atoms
+// ---------------------------------------------------------------------
+
+// mp4Boxes calls fn for every box in b with its type and payload.
+func mp4Boxes(b []byte, fn func(typ string, payload []byte)) {
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b))
+		typ := string(b[4:8])
+		hdr := 8
+		switch size {
+		case 0:
+			size = len(b)
+		case 1:
+			if len(b) < 16 {
+				return
+			}
+			size, hdr = int(binary.BigEndian.Uint64(b[8:])), 16
+		}
+		if size < hdr || size > len(b) {
+			return
+		}
+		fn(typ, b[hdr:size])
+		b = b[size:]
+	}
+}
+
+func parseMP4(f *os.File, size int64, mi *MediaInfo) error {
+	// find moov among the top-level boxes without reading mdat
+	var off int64
+	var hdr [16]byte
+	for off+8 <= size {
+		if _, err := f.ReadAt(hdr[:8], off); err != nil {
+			return err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(hdr[:]))
+		typ := string(hdr[4:8])
+		hdrLen := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - off
+		case 1:
+			if _, err := f.ReadAt(hdr[8:16], off+8); err != nil {
+				return err
+			}
+			boxSize, hdrLen = int64(binary.BigEndian.Uint64(hdr[8:])), 16
+		}
+		if boxSize < hdrLen {
+			return fmt.Errorf("%s: corrupt MP4 box", mi.Path)
+		}
+		if typ == "moov" {
+			if boxSize > maxMediaHeader {
+				return fmt.Errorf("%s: MP4 header too large", mi.Path)
+			}
+			moov := make([]byte, boxSize-hdrLen)
+			if _, err := f.ReadAt(moov, off+hdrLen); err != nil {
+				return err
+			}
+			parseMoov(moov, mi)
+			return nil
+		}
+		off += boxSize
+	}
+	return fmt.Errorf("%s: no MP4 header", mi.Path)
+}
+
+func parseMoov(moov []byte, mi *MediaInfo) {
+	mi.Video = false
+	var walk func(b []byte)
+	walk = func(b []byte) {
+		mp4Boxes(b, func(typ string, p []byte) {
+			switch typ {
+			case "trak", "mdia", "udta", "ilst":
+				walk(p)
+			case "meta": // a full box: version and flags come first
+				if len(p) > 4 {
+					walk(p[4:])
+				}
+			case "mvhd":
+				if len(p) >= 32 && p[0] == 1 {
+					scale := binary.BigEndian.Uint32(p[20:])
+					if scale > 0 {
+						mi.Duration = mediaDuration(float64(binary.BigEndian.Uint64(p[24:])) / float64(scale))
+					}
+				} else if len(p) >= 20 {
+					scale := binary.BigEndian.Uint32(p[12:])
+					if scale > 0 {
+						mi.Duration = mediaDuration(float64(binary.BigEndian.Uint32(p[16:])) / float64(scale))
+					}
+				}
+			case "hdlr":
+				if len(p) >= 12 && string(p[8:12]) == "vide" {
+					mi.Video = true
+				}
+			case "\xa9nam", "\xa9ART", "\xa9alb":
+				mp4Boxes(p, func(t string, d []byte) {
+					if t != "data" || len(d) < 8 {
+						return
+					}
+					v := strings.TrimSpace(string(d[8:]))
+					switch typ {
+					case "\xa9nam":
+						mi.Title = v
+					case "\xa9ART":
+						mi.Artist = v
+					default:
+						mi.Album = v
+					}
+				})
+			}
+		})
+	}
+	walk(moov)
+}
+
+// ---------------------------------------------------------------------
+//  3) Matroska / WebM (EBML)
+// ---------------------------------------------------------------------
+
+// Matroska element IDs we read
+const (
+	mkvSegment    = 0x18538067
+	mkvInfo       = 0x1549A966
+	mkvTimescale  = 0x2AD7B1
+	mkvDuration   = 0x4489
+	mkvTitle      = 0x7BA9
+	mkvTracks     = 0x1654AE6B
+	mkvTrackEntry = 0xAE
+	mkvTrackType  = 0x83
+	mkvTags       = 0x1254C367
+	mkvTag        = 0x7373
+	mkvSimpleTag  = 0x67C8
+	mkvTagName    = 0x45A3
+	mkvTagString  = 0x4487
+	mkvCluster    = 0x1F43B675
+)
+
+// ebmlVint reads a variable-length integer. IDs keep their length marker;
+// sizes do not. unknown is set for sizes with every value bit set.
+func ebmlVint(r io.ByteReader, keepMarker bool) (v uint64, n int, unknown bool, err error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	n = bits.LeadingZeros8(first) + 1
+	if n > 8 {
+		return 0, 0, false, fmt.Errorf("invalid EBML integer")
+	}
+	v = uint64(first)
+	if !keepMarker {
+		v &= uint64(0xFF >> n)
+	}
+	allOnes := v == uint64(0xFF>>n)
+	for i := 1; i < n; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, false, err
+		}
+		v = v<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	return v, n, !keepMarker && allOnes, nil
+}
+
+// ebmlElements calls fn for every child element in b.
+func ebmlElements(b []byte, fn func(id uint64, data []byte)) {
+	r := bytes.NewReader(b)
+	for r.Len() > 0 {
+		id, _, _, err := ebmlVint(r, true)
+		if err != nil {
+			return
+		}
+		size, _, unknown, err := ebmlVint(r, false)
+		if err != nil || unknown || size > uint64(r.Len()) {
+			return
+		}
+		off := len(b) - r.Len()
+		fn(id, b[off:off+int(size)])
+		r.Seek(int64(size), io.SeekCurrent)
+	}
+}
+
+func ebmlUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func parseMatroska(f *os.File, size int64, mi *MediaInfo) error {
+	br := bufio.NewReader(f)
+	var pos int64
+	readHeader := func() (id, elemSize uint64, unknown bool, err error) {
+		id, n1, _, err := ebmlVint(br, true)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		elemSize, n2, unknown, err := ebmlVint(br, false)
+		pos += int64(n1 + n2)
+		return id, elemSize, unknown, err
+	}
+	skip := func(n int64) error {
+		if _, err := f.Seek(pos+n, io.SeekStart); err != nil {
+			return err
+		}
+		pos += n
+		br.Reset(f)
+		return nil
+	}
+
+	timescale := uint64(1000000) // ns per tick
+	var duration float64
+	mi.Video = false
+elements:
+	for pos < size {
+		id, elemSize, unknown, err := readHeader()
+		if err != nil {
+			break elements
+		}
+		switch id {
+		case mkvSegment:
+			continue // descend: the children follow
+		case mkvCluster:
+			if unknown {
+				break elements // cannot skip a cluster of unknown size
+			}
+		case mkvInfo, mkvTracks, mkvTags:
+			if unknown || elemSize > maxMediaHeader {
+				return fmt.Errorf("%s: corrupt Matroska header", mi.Path)
+			}
+			data := make([]byte, elemSize)
+			if _, err := io.ReadFull(br, data); err != nil {
+				return err
+			}
+			pos += int64(elemSize)
+			parseMatroskaElement(id, data, mi, &timescale, &duration)
+			continue
+		}
+		if unknown {
+			break elements // no way to find the next element
+		}
+		if err := skip(int64(elemSize)); err != nil {
+			return err
+		}
+	}
+	if duration > 0 {
+		mi.Duration = mediaDuration(duration * float64(timescale) / float64(time.Second))
+	}
+	return nil
+}
+
+func parseMatroskaElement(id uint64, data []byte, mi *MediaInfo, timescale *uint64, duration *float64) {
+	switch id {
+	case mkvInfo:
+		ebmlElements(data, func(id uint64, d []byte) {
+			switch id {
+			case mkvTimescale:
+				*timescale = ebmlUint(d)
+			case mkvDuration:
+				*duration = ebmlFloat(d)
+			case mkvTitle:
+				mi.Title = strings.TrimSpace(string(d))
+			}
+		})
+	case mkvTracks:
+		ebmlElements(data, func(id uint64, d []byte) {
+			if id != mkvTrackEntry {
+				return
+			}
+			ebmlElements(d, func(id uint64, d []byte) {
+				if id == mkvTrackType && ebmlUint(d) == 1 {
+					mi.Video = true
+				}
+			})
+		})
+	case mkvTags:
+		ebmlElements(data, func(id uint64, d []byte) {
+			if id != mkvTag {
+				return
+			}
+			ebmlElements(d, func(id uint64, d []byte) {
+				if id != mkvSimpleTag {
+					return
+				}
+				var name, value string
+				ebmlElements(d, func(id uint64, d []byte) {
+					switch id {
+					case mkvTagName:
+						name = strings.ToUpper(string(d))
+					case mkvTagString:
+						value = strings.TrimSpace(string(d))
+					}
+				})
+				setMediaTag(mi, name, value)
+			})
+		})
+	}
+}
+
+// setMediaTag stores a Vorbis-comment style NAME=value tag.
+func setMediaTag(mi *MediaInfo, name, value string) {
+	if value == "" {
+		return
+	}
+	switch name {
+	case "TITLE":
+		mi.Title = value
+	case "ARTIST":
+		mi.Artist = value
+	case "ALBUM":
+		mi.Album = value
+	}
+}
+
+// ---------------------------------------------------------------------
+//  4) FLAC and WAV
+// ---------------------------------------------------------------------
+
+func parseFLAC(f *os.File, mi *MediaInfo) error {
+	r := bufio.NewReader(f)
+	if _, err := r.Discard(4); err != nil {
+		return err
+	}
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return err
+		}
+		last, typ := hdr[0]&0x80 != 0, hdr[0]&0x7F
+		n := int(hdr[1])<<16 | int(hdr[2])<<8 | int(hdr[3])
+		block := make([]byte, n)
+		if _, err := io.ReadFull(r, block); err != nil {
+			return err
+		}
+		switch typ {
+		case 0: // STREAMINFO
+			if n >= 18 {
+				rate := uint64(block[10])<<12 | uint64(block[11])<<4 | uint64(block[12])>>4
+				samples := uint64(block[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(block[14:]))
+				if rate > 0 {
+					mi.Duration = mediaDuration(float64(samples) / float64(rate))
+				}
+			}
+		case 4: // VORBIS_COMMENT, little-endian lengths
+			parseVorbisComments(block, mi)
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+func parseVorbisComments(b []byte, mi *MediaInfo) {
+	next := func() (string, bool) {
+		if len(b) < 4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if n > len(b)-4 {
+			return "", false
+		}
+		s := string(b[4 : 4+n])
+		b = b[4+n:]
+		return s, true
+	}
+	if _, ok := next(); !ok { // vendor
+		return
+	}
+	if len(b) < 4 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	for i := 0; i < count; i++ {
+		c, ok := next()
+		if !ok {
+			return
+		}
+		if k, v, ok := strings.Cut(c, "="); ok {
+			setMediaTag(mi, strings.ToUpper(k), strings.TrimSpace(v))
+		}
+	}
+}
+
+// wavFormat is the part of a WAV "fmt " chunk we need.
+type wavFormat struct {
+	format, channels, bitsPerSample uint16
+	rate, byteRate                  uint32
+}
+
+// parseWAV reads the format, duration and INFO tags. If pcm is not nil it
+// is called with the format and a reader over the sample data.
+func parseWAV(f *os.File, mi *MediaInfo, pcm func(wavFormat, io.Reader)) error {
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil {
+		return err
+	}
+	var wf wavFormat
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(f, hdr[:]); err != nil {
+			return nil
+		}
+		id, n := string(hdr[:4]), int64(binary.LittleEndian.Uint32(hdr[4:]))
+		next, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		next += n + n&1 // chunks are word aligned
+		switch id {
+		case "fmt ":
+			var b [16]byte
+			if _, err := io.ReadFull(f, b[:]); err != nil {
+				return err
+			}
+			wf = wavFormat{
+				format:        binary.LittleEndian.Uint16(b[0:]),
+				channels:      binary.LittleEndian.Uint16(b[2:]),
+				rate:          binary.LittleEndian.Uint32(b[4:]),
+				byteRate:      binary.LittleEndian.Uint32(b[8:]),
+				bitsPerSample: binary.LittleEndian.Uint16(b[14:]),
+			}
+		case "data":
+			if wf.byteRate > 0 {
+				mi.Duration = mediaDuration(float64(n) / float64(wf.byteRate))
+				mi.Bitrate = int(wf.byteRate * 8 / 1000)
+			}
+			if pcm != nil {
+				pcm(wf, io.LimitReader(f, n))
+			}
+		case "LIST":
+			if n > maxMediaHeader {
+				break
+			}
+			b := make([]byte, n)
+			if _, err := io.ReadFull(f, b); err != nil || len(b) < 4 || string(b[:4]) != "INFO" {
+				break
+			}
+			for b = b[4:]; len(b) >= 8; {
+				sub, m := string(b[:4]), int(binary.LittleEndian.Uint32(b[4:]))
+				if 8+m > len(b) {
+					break
+				}
+				v := strings.TrimSpace(strings.TrimRight(string(b[8:8+m]), "\x00"))
+				switch sub {
+				case "INAM":
+					mi.Title = v
+				case "IART":
+					mi.Artist = v
+				case "IPRD":
+					mi.Album = v
+				}
+				b = b[min(8+m+m&1, len(b)):] // the last entry may lack its pad byte
+			}
+		}
+		if _, err := f.Seek(next, io.SeekStart); err != nil {
+			return err
+		}
+	}
+}
+
+// ---------------------------------------------------------------------
+//  5) Audio Fingerprints
+// ---------------------------------------------------------------------
+
+// fpcalcPath is the Chromaprint command line tool, if it is installed.
+var fpcalcPath = sync.OnceValue(func() string {
+	p, _ := exec.LookPath("fpcalc")
+	return p
+})
+
+// fingerprintAudio computes the fingerprint of mi's file. Chromaprint's
+// fpcalc is used when installed; otherwise only PCM WAV files get a
+// fingerprint, from their loudness envelope.
+func fingerprintAudio(mi *MediaInfo) {
+	if mi.Video {
+		return
+	}
+	if p := fpcalcPath(); p != "" {
+		out, err := exec.Command(p, "-raw", "-length", fpcalcLength, mi.Path).Output()
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			k, v, _ := strings.Cut(strings.TrimSpace(line), "=")
+			switch k {
+			case "DURATION":
+				if secs, err := strconv.ParseFloat(v, 64); err == nil && mi.Duration == 0 {
+					mi.Duration = mediaDuration(secs)
+				}
+			case "FINGERPRINT":
+				for _, field := range strings.Split(v, ",") {
+					if n, err := strconv.ParseInt(field, 10, 64); err == nil {
+						mi.Fingerprint = append(mi.Fingerprint, uint32(n))
+					}
+				}
+			}
+		}
+		if len(mi.Fingerprint) > 0 {
+			mi.FingerprintKind = "chromaprint"
+		}
+		return
+	}
+
+	if strings.ToLower(filepath.Ext(mi.Path)) != ".wav" {
+		return
+	}
+	f, err := os.Open(mi.Path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	parseWAV(f, &MediaInfo{}, func(wf wavFormat, r io.Reader) {
+		if fp := envelopeFingerprint(wf, r); len(fp) > 0 {
+			mi.Fingerprint, mi.FingerprintKind = fp, "envelope"
+		}
+	})
+}
+
+// envelopeFingerprint reduces 16-bit PCM to its loudness in 20 ms windows.
+// Each sub-fingerprint covers 32 windows; bit i is set when window i is
+// louder than the average of the 32, so the fingerprint survives volume
+// changes, resampling and downmixing. Sub-fingerprints start every 8 windows.
+func envelopeFingerprint(wf wavFormat, r io.Reader) []uint32 {
+	if wf.format != 1 || wf.bitsPerSample != 16 || wf.channels == 0 || wf.rate < 8000 {
+		return nil
+	}
+	const windowsPerSecond = 50
+	const maxWindows = windowsPerSecond * 120 // the first two minutes, like fpcalc
+	perWindow := int(wf.rate) / windowsPerSecond
+	br := bufio.NewReader(r)
+	sample := make([]byte, 2*int(wf.channels))
+
+	var env []float64
+read:
+	for len(env) < maxWindows {
+		var e float64
+		for i := 0; i < perWindow; i++ {
+			if _, err := io.ReadFull(br, sample); err != nil {
+				break read
+			}
+			var sum float64
+			for c := 0; c < int(wf.channels); c++ {
+				sum += float64(int16(binary.LittleEndian.Uint16(sample[2*c:])))
+			}
+			sum /= float64(wf.channels)
+			e += sum * sum
+		}
+		env = append(env, math.Log1p(e/float64(perWindow)))
+	}
+
+	var fp []uint32
+	for start := 0; start+32 <= len(env); start += 8 {
+		var mean float64
+		for _, v := range env[start : start+32] {
+			mean += v
+		}
+		mean /= 32
+		var word uint32
+		for i, v := range env[start : start+32] {
+			if v > mean {
+				word |= 1 << uint(i)
+			}
+		}
+		fp = append(fp, word)
+	}
+	return fp
+}
+
+// fingerprintSimilarity returns the share of matching bits (0..1) at the
+// best alignment of a and b, allowing for a few seconds of offset.
+func fingerprintSimilarity(a, b []uint32) float64 {
+	const maxShift = 24
+	minOverlap := min(len(a), len(b)) / 2
+	best := 0.0
+	for shift := -maxShift; shift <= maxShift; shift++ {
+		var diff, n int
+		for i := range a {
+			j := i + shift
+			if j < 0 || j >= len(b) {
+				continue
+			}
+			diff += bits.OnesCount32(a[i] ^ b[j])
+			n++
+		}
+		if n == 0 || n < minOverlap {
+			continue
+		}
+		if sim := 1 - float64(diff)/float64(32*n); sim > best {
+			best = sim
+		}
+	}
+	return best
+}
+
+// ---------------------------------------------------------------------
+//  6) Matching
+// ---------------------------------------------------------------------
+
+// normalizeMediaText lowercases s and keeps only letters and digits, so
+// "Song (Remastered)" and "song remastered" compare equal.
+func normalizeMediaText(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func mediaBaseName(path string) string {
+	return normalizeMediaText(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+}
+
+// mediaConfidence scores how likely a and b are the same recording (0..1)
+// and lists the evidence.
+func mediaConfidence(a, b *MediaInfo) (float64, []string) {
+	if a.Video != b.Video {
+		return 0, nil
+	}
+	var score float64
+	var why []string
+
+	if a.Duration > 0 && b.Duration > 0 {
+		d := a.Duration - b.Duration
+		if d < 0 {
+			d = -d
+		}
+		switch {
+		case d > mediaDurationWindow:
+			return 0, nil
+		case d <= time.Second:
+			score += 0.3
+			why = append(why, "duration")
+		case d <= 3*time.Second:
+			score += 0.2
+			why = append(why, "duration ±3s")
+		default:
+			score += 0.05
+		}
+	}
+
+	if ta, tb := normalizeMediaText(a.Title), normalizeMediaText(b.Title); ta != "" && tb != "" {
+		if ta == tb {
+			score += 0.35
+			why = append(why, "title")
+		} else {
+			score -= 0.3
+		}
+	} else if mediaBaseName(a.Path) == mediaBaseName(b.Path) {
+		score += 0.2
+		why = append(why, "file name")
+	}
+	if aa, ab := normalizeMediaText(a.Artist), normalizeMediaText(b.Artist); aa != "" && ab != "" {
+		if aa == ab {
+			score += 0.15
+			why = append(why, "artist")
+		} else {
+			score -= 0.2
+		}
+	}
+
+	if a.FingerprintKind != "" && a.FingerprintKind == b.FingerprintKind {
+		sim := fingerprintSimilarity(a.Fingerprint, b.Fingerprint)
+		// unrelated audio still matches about two thirds of the bits at its best offset
+		switch {
+		case sim >= 0.9:
+			score += 0.5
+		case sim >= 0.8:
+			score += 0.35
+		default:
+			score -= 0.4
+		}
+		if sim >= 0.8 {
+			why = append(why, fmt.Sprintf("fingerprint %.0f%%", sim*100))
+		}
+	}
+	return math.Max(0, math.Min(1, score)), why
+}
+
+// MediaGroup is a set of files that are probably the same recording.
+// Confidence is the weakest link that joined the group.
+type MediaGroup struct {
+	Files      []*MediaInfo
+	Confidence float64
+	Evidence   []string
+}
+
+// groupMedia links files whose confidence is at least minConfidence.
+// Only files of similar length (or sharing a title or name when the length
+// is unknown) are compared with each other.
+func groupMedia(infos []*MediaInfo, minConfidence float64) []MediaGroup {
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Duration != infos[j].Duration {
+			return infos[i].Duration < infos[j].Duration
+		}
+		return infos[i].Path < infos[j].Path
+	})
+
+	parent := make([]int, len(infos))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	weakest := make(map[int]float64)
+	evidence := make(map[int][]string)
+	link := func(i, j int) {
+		c, why := mediaConfidence(infos[i], infos[j])
+		if c < minConfidence {
+			return
+		}
+		ri, rj := find(i), find(j)
+		if ri == rj {
+			return
+		}
+		w := c
+		for _, r := range []int{ri, rj} {
+			if v, ok := weakest[r]; ok && v < w {
+				w = v
+			}
+		}
+		parent[rj] = ri
+		weakest[ri] = w
+		for _, e := range append(evidence[rj], why...) {
+			if !contains(evidence[ri], e) {
+				evidence[ri] = append(evidence[ri], e)
+			}
+		}
+	}
+
+	byKey := make(map[string][]int)
+	for i, mi := range infos {
+		if mi.Duration == 0 {
+			for _, k := range []string{"t:" + normalizeMediaText(mi.Title), "n:" + mediaBaseName(mi.Path)} {
+				if len(k) > 2 {
+					byKey[k] = append(byKey[k], i)
+				}
+			}
+			continue
+		}
+		for j := i + 1; j < len(infos) && infos[j].Duration-mi.Duration <= mediaDurationWindow; j++ {
+			link(i, j)
+		}
+	}
+	for i, mi := range infos {
+		for _, k := range []string{"t:" + normalizeMediaText(mi.Title), "n:" + mediaBaseName(mi.Path)} {
+			for _, j := range byKey[k] {
+				if j != i {
+					link(i, j)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]*MediaInfo)
+	var roots []int
+	for i, mi := range infos {
+		r := find(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], mi)
+	}
+	var groups []MediaGroup
+	for _, r := range roots {
+		if len(members[r]) > 1 {
+			groups = append(groups, MediaGroup{Files: members[r], Confidence: weakest[r], Evidence: evidence[r]})
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Confidence > groups[j].Confidence })
+	return groups
+}
+
+func formatMediaDuration(d time.Duration) string {
+	if d <= 0 {
+		return "?:??"
+	}
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// ---------------------------------------------------------------------
+//  7) Media Scan
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) showScanningMedia(roots []ScanRoot, filter FileFilter, minConfidence float64) {
+	pb := widget.NewProgressBarInfinite()
+	lbl := widget.NewLabel("Scanning for duplicate media...")
+	vbox := container.NewVBox(lbl, pb)
+
+	dlg := dialog.NewCustom("Please Wait", "Cancel", vbox, s.mainWindow)
+	scan := s.startScan(dlg)
+
+	go func() {
+		files, skipped, e := s.scanRoots(roots, filter)
+		if e != nil {
+			s.postUI(func() {
+				if !s.finishScan(scan) {
+					return
+				}
+				dlg.Hide()
+				dialog.ShowError(e, s.mainWindow)
+			})
+			return
+		}
+		var media []string
+		for _, f := range files {
+			if isMediaFile(f) {
+				media = append(media, f)
+			}
+		}
+
+		var infos []*MediaInfo
+		for i, f := range media {
+			if !s.scanRunning(scan) {
+				return
+			}
+			if i%imageProgressUpdate == 0 {
+				text := fmt.Sprintf("Reading media files... %d of %d", i, len(media))
+				s.postUI(func() { lbl.SetText(text) })
+			}
+			mi, err := readMediaInfo(f)
+			if err != nil {
+				continue
+			}
+			fingerprintAudio(mi)
+			infos = append(infos, mi)
+		}
+
+		m := make(map[string][]string)
+		var items []*FileItem
+		for i, g := range groupMedia(infos, minConfidence) {
+			key := fmt.Sprintf("%s%d", mediaGroupPrefix, i+1)
+			for _, mi := range g.Files {
+				m[key] = append(m[key], mi.Path)
+				note := fmt.Sprintf("media group %d, %.0f%% confidence (%s) — %s", i+1, g.Confidence*100,
+					strings.Join(g.Evidence, ", "), formatMediaDuration(mi.Duration))
+				if mi.Bitrate > 0 {
+					note += fmt.Sprintf(", %d kbps", mi.Bitrate)
+				}
+				items = append(items, &FileItem{filePath: mi.Path, size: fileSize(mi.Path), note: note})
+			}
+		}
+		items, m = applyRoles(roots, items, m)
+		stampModTimes(items)
+
+		s.postUI(func() {
+			if !s.finishScan(scan) {
+				return
+			}
+			s.mu.Lock()
+			s.allFileItems = items
+			s.allDuplicates = m
+			s.dfAlgorithm, s.dfVerified = mediaAlgorithm, false
+			s.mu.Unlock()
+			dlg.Hide()
+
+			if len(items) == 0 {
+				s.showScanResult("No Duplicate Media", fmt.Sprintf("No duplicate recordings found among %d media file(s).", len(infos)), skipped)
+			} else {
+				msg := fmt.Sprintf("Found %d file(s) in %d group(s) of the same recording.", len(items), len(m))
+				s.showScanResult("Scan Complete", msg, skipped)
+			}
+			s.refreshDuplicates()
+			s.watchDuplicates(s.dfWatchCheck.Checked)
+		})
+	}()
+}
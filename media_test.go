@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Small media files, built in memory. Each holds just enough for the
+// parsers: tags, a duration and, for video, a video track.
+
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func le16(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+
+func join(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+// id3Frame is an ID3v2.3 text frame in ISO-8859-1.
+func id3Frame(id, text string) []byte {
+	body := append([]byte{0}, text...)
+	return join([]byte(id), be32(uint32(len(body))), []byte{0, 0}, body)
+}
+
+func id3v2Tag(frames ...[]byte) []byte {
+	body := join(frames...)
+	n := len(body)
+	size := []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+	return join([]byte("ID3"), []byte{3, 0, 0}, size, body)
+}
+
+func id3v1Tag(title, artist, album string) []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	return tag
+}
+
+// mp3Frames is one second of 128 kbps MPEG-1 Layer III: a frame header
+// followed by silence.
+func mp3Frames() []byte {
+	audio := make([]byte, 16000)
+	copy(audio, []byte{0xFF, 0xFB, 0x90, 0x64})
+	return audio
+}
+
+func mp4Box(typ string, payload ...[]byte) []byte {
+	body := join(payload...)
+	return join(be32(uint32(8+len(body))), []byte(typ), body)
+}
+
+func mp4File(title string, seconds uint32, handler string) []byte {
+	mvhd := join(make([]byte, 12), be32(1000), be32(seconds*1000), make([]byte, 80))
+	hdlr := join(make([]byte, 8), []byte(handler), make([]byte, 12))
+	data := mp4Box("data", make([]byte, 8), []byte(title))
+	return join(
+		mp4Box("ftyp", []byte("isom"), make([]byte, 4)),
+		mp4Box("moov",
+			mp4Box("mvhd", mvhd),
+			mp4Box("trak", mp4Box("mdia", mp4Box("hdlr", hdlr))),
+			mp4Box("udta", mp4Box("meta", make([]byte, 4), mp4Box("ilst", mp4Box("\xa9nam", data)))),
+		),
+		mp4Box("mdat", make([]byte, 64)),
+	)
+}
+
+// ebml encodes an element with an eight-byte size.
+func ebml(id uint32, data ...[]byte) []byte {
+	body := join(data...)
+	idBytes := be32(id)
+	for len(idBytes) > 1 && idBytes[0] == 0 {
+		idBytes = idBytes[1:]
+	}
+	size := binary.BigEndian.AppendUint64(nil, uint64(len(body)))
+	size[0] = 0x01
+	return join(idBytes, size, body)
+}
+
+func matroskaFile(title string, durationMS float64, video bool) []byte {
+	trackType := byte(2) // audio
+	if video {
+		trackType = 1
+	}
+	segment := join(
+		ebml(mkvInfo,
+			ebml(mkvTimescale, be32(1000000)),
+			ebml(mkvDuration, binary.BigEndian.AppendUint64(nil, math.Float64bits(durationMS))),
+		),
+		ebml(mkvTracks, ebml(mkvTrackEntry, ebml(mkvTrackType, []byte{trackType}))),
+		ebml(mkvTags, ebml(mkvTag, ebml(mkvSimpleTag,
+			ebml(mkvTagName, []byte("TITLE")),
+			ebml(mkvTagString, []byte(title)),
+		))),
+		ebml(mkvCluster, make([]byte, 32)),
+	)
+	// the segment has an unknown size, as written by live encoders
+	return join(ebml(0x1A45DFA3, ebml(0x4282, []byte("webm"))),
+		[]byte{0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, segment)
+}
+
+func flacFile(seconds uint32, comments ...string) []byte {
+	info := make([]byte, 34)
+	info[10], info[11], info[12] = 0x0A, 0xC4, 0x42 // 44100 Hz, stereo
+	info[13] = 0xF0                                 // 16 bits
+	binary.BigEndian.PutUint32(info[14:], seconds*44100)
+	vc := join(le32(4), []byte("test"), le32(uint32(len(comments))))
+	for _, c := range comments {
+		vc = join(vc, le32(uint32(len(c))), []byte(c))
+	}
+	block := func(typ byte, b []byte) []byte {
+		n := len(b)
+		return join([]byte{typ, byte(n >> 16), byte(n >> 8), byte(n)}, b)
+	}
+	return join([]byte("fLaC"), block(0, info), block(0x80|4, vc))
+}
+
+// wavFile is 16-bit mono PCM. With pad unset an odd-length title is the
+// last thing in the LIST chunk, without the pad byte of its own that some
+// writers leave out; the chunk itself is still padded.
+func wavFile(title string, seconds uint32, pad bool) []byte {
+	fmtChunk := join(le16(1), le16(1), le32(8000), le32(16000), le16(2), le16(16))
+	info := join([]byte("INFO"), []byte("INAM"), le32(uint32(len(title))), []byte(title))
+	if pad && len(title)%2 == 1 {
+		info = append(info, 0)
+	}
+	list := join([]byte("LIST"), le32(uint32(len(info))), info)
+	if len(info)%2 == 1 {
+		list = append(list, 0)
+	}
+	data := make([]byte, 16000*seconds)
+	body := join([]byte("WAVE"),
+		[]byte("fmt "), le32(uint32(len(fmtChunk))), fmtChunk,
+		list,
+		[]byte("data"), le32(uint32(len(data))), data,
+	)
+	return join([]byte("RIFF"), le32(uint32(len(body))), body)
+}
+
+func writeMediaFixture(t testing.TB, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReadMediaInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    []byte
+		want    MediaInfo
+		wantErr bool
+	}{
+		{
+			name: "mp3 with ID3v2",
+			file: "song.mp3",
+			data: join(id3v2Tag(id3Frame("TIT2", "Song"), id3Frame("TPE1", "Band"), id3Frame("TALB", "Record")), mp3Frames()),
+			want: MediaInfo{Title: "Song", Artist: "Band", Album: "Record", Duration: time.Second, Bitrate: 128},
+		},
+		{
+			name: "mp3 with ID3v2 length",
+			file: "song.mp3",
+			data: join(id3v2Tag(id3Frame("TIT2", "Song"), id3Frame("TLEN", "215000")), mp3Frames()),
+			want: MediaInfo{Title: "Song", Duration: 215 * time.Second},
+		},
+		{
+			name: "mp3 with ID3v1",
+			file: "song.mp3",
+			data: join(mp3Frames(), id3v1Tag("Old Song", "Old Band", "Old Record")),
+			want: MediaInfo{Title: "Old Song", Artist: "Old Band", Album: "Old Record", Duration: time.Second, Bitrate: 128},
+		},
+		{
+			name: "mp4 video",
+			file: "clip.mp4",
+			data: mp4File("Clip", 90, "vide"),
+			want: MediaInfo{Video: true, Title: "Clip", Duration: 90 * time.Second},
+		},
+		{
+			name: "m4a audio",
+			file: "track.m4a",
+			data: mp4File("Track", 3, "soun"),
+			want: MediaInfo{Title: "Track", Duration: 3 * time.Second},
+		},
+		{
+			name: "matroska video",
+			file: "film.mkv",
+			data: matroskaFile("Film", 5000, true),
+			want: MediaInfo{Video: true, Title: "Film", Duration: 5 * time.Second},
+		},
+		{
+			name: "webm audio only",
+			file: "voice.webm",
+			data: matroskaFile("Voice", 2500, false),
+			want: MediaInfo{Title: "Voice", Duration: 2500 * time.Millisecond},
+		},
+		{
+			name: "flac",
+			file: "track.flac",
+			data: flacFile(10, "TITLE=Flac Song", "artist=Flac Band", "ALBUM=Flac Record", "NOEQUALS"),
+			want: MediaInfo{Title: "Flac Song", Artist: "Flac Band", Album: "Flac Record", Duration: 10 * time.Second},
+		},
+		{
+			name: "wav",
+			file: "take.wav",
+			data: wavFile("Take", 2, true),
+			want: MediaInfo{Title: "Take", Duration: 2 * time.Second, Bitrate: 128},
+		},
+		{
+			name: "wav with unpadded tag",
+			file: "take.wav",
+			data: wavFile("Odd", 1, false),
+			want: MediaInfo{Title: "Odd", Duration: time.Second, Bitrate: 128},
+		},
+		{name: "too short", file: "x.mp3", data: []byte("ID3"), wantErr: true},
+		{name: "unknown format", file: "x.mp3", data: []byte("this is not a media file"), wantErr: true},
+		{name: "mp4 without moov", file: "x.mp4", data: mp4Box("ftyp", []byte("isom"), make([]byte, 4)), wantErr: true},
+		{name: "flac cut short", file: "x.flac", data: flacFile(10)[:20], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := writeMediaFixture(t, tt.file, tt.data)
+			mi, err := readMediaInfo(p)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want an error, got %+v", mi)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Path = p
+			got := *mi
+			if tt.want.Bitrate == 0 {
+				got.Bitrate = 0 // derived from the file size
+			}
+			if got.Path != tt.want.Path || got.Video != tt.want.Video || got.Title != tt.want.Title ||
+				got.Artist != tt.want.Artist || got.Album != tt.want.Album ||
+				got.Duration != tt.want.Duration || got.Bitrate != tt.want.Bitrate {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestID3Text(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"empty", nil, ""},
+		{"latin-1", []byte{0, 'C', 'a', 'f', 0xE9}, "Café"},
+		{"utf-16 le with bom", []byte{1, 0xFF, 0xFE, 'H', 0, 'i', 0}, "Hi"},
+		{"utf-16 be with bom", []byte{1, 0xFE, 0xFF, 0, 'H', 0, 'i'}, "Hi"},
+		{"utf-16 be", []byte{2, 0, 'H', 0, 'i'}, "Hi"},
+		{"utf-8", append([]byte{3}, "Café"...), "Café"},
+		{"first of several values", append([]byte{3}, "One\x00Two"...), "One"},
+		{"trimmed", append([]byte{3}, "  Padded  "...), "Padded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := id3Text(tt.in); got != tt.want {
+				t.Errorf("id3Text(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzParseMedia feeds arbitrary bytes to the container parsers, which
+// must return an error or a result but never panic or hang.
+func FuzzParseMedia(f *testing.F) {
+	seeds := [][]byte{
+		join(id3v2Tag(id3Frame("TIT2", "Song")), mp3Frames()[:64]),
+		join(mp3Frames()[:64], id3v1Tag("a", "b", "c")),
+		mp4File("Clip", 90, "vide"),
+		matroskaFile("Film", 5000, true),
+		flacFile(10, "TITLE=x"),
+		wavFile("Take", 0, true),
+		wavFile("Odd", 0, false),
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := filepath.Join(dir, "fuzz.media")
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mi, err := readMediaInfo(p)
+		if err == nil && mi.Duration < 0 {
+			t.Errorf("negative duration %v", mi.Duration)
+		}
+	})
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const metricsFilePath = "metrics.json" // Local time-series store for the sampler
+
+// ---------------------------------------------------------------------
+//  1) Time-Series Store
+// ---------------------------------------------------------------------
+
+// MetricPoint is one (possibly averaged) reading. Field names are kept short
+// because the store holds a few thousand of them.
+type MetricPoint struct {
+	T    int64   `json:"t"` // unix seconds
+	CPU  float64 `json:"c"`
+	Mem  float64 `json:"m"`
+	Disk float64 `json:"d"` // used % of the fullest volume
+}
+
+// metricTier keeps points at a fixed resolution for a fixed time. A Step of 0
+// stores every sample as-is; otherwise samples are averaged into Step-wide buckets.
+type metricTier struct {
+	Name      string
+	Step      time.Duration
+	Retention time.Duration
+	Points    []MetricPoint
+
+	bucket int64 // start of the bucket being accumulated, unix seconds
+	sum    MetricPoint
+	count  int
+}
+
+func (t *metricTier) add(p MetricPoint) {
+	if t.Step == 0 {
+		t.Points = append(t.Points, p)
+		return
+	}
+	step := int64(t.Step / time.Second)
+	b := p.T - p.T%step
+	if t.count > 0 && b != t.bucket {
+		t.flush()
+	}
+	t.bucket = b
+	t.sum.CPU += p.CPU
+	t.sum.Mem += p.Mem
+	t.sum.Disk += p.Disk
+	t.count++
+}
+
+func (t *metricTier) flush() {
+	if t.count == 0 {
+		return
+	}
+	n := float64(t.count)
+	t.Points = append(t.Points, MetricPoint{
+		T:    t.bucket,
+		CPU:  t.sum.CPU / n,
+		Mem:  t.sum.Mem / n,
+		Disk: t.sum.Disk / n,
+	})
+	t.sum = MetricPoint{}
+	t.count = 0
+}
+
+func (t *metricTier) prune(now time.Time) {
+	cutoff := now.Add(-t.Retention).Unix()
+	i := 0
+	for i < len(t.Points) && t.Points[i].T < cutoff {
+		i++
+	}
+	if i > 0 {
+		t.Points = append([]MetricPoint{}, t.Points[i:]...)
+	}
+}
+
+// MetricsStore records sampler output into several tiers of decreasing
+// resolution, so the last hour is kept at full detail and the last week
+// at ten-minute averages.
+type MetricsStore struct {
+	mu        sync.Mutex
+	tiers     []*metricTier
+	lastSaved time.Time
+
+	saveMu sync.Mutex // held for a whole save, so writes do not interleave
+}
+
+func newMetricsStore() *MetricsStore {
+	return &MetricsStore{
+		tiers: []*metricTier{
+			{Name: "raw", Step: 0, Retention: time.Hour},
+			{Name: "1m", Step: time.Minute, Retention: 24 * time.Hour},
+			{Name: "10m", Step: 10 * time.Minute, Retention: 7 * 24 * time.Hour},
+			{Name: "1h", Step: time.Hour, Retention: 90 * 24 * time.Hour},
+		},
+	}
+}
+
+type storedTier struct {
+	Name   string
+	Points []MetricPoint
+	Open   *storedBucket `json:",omitempty"`
+}
+
+// storedBucket is a tier's partly filled bucket, kept so a restart does not
+// lose the samples averaged so far.
+type storedBucket struct {
+	Start int64
+	Sum   MetricPoint
+	Count int
+}
+
+// loadMetricsStore restores the tiers saved by a previous run.
+func loadMetricsStore() (*MetricsStore, error) {
+	ms := newMetricsStore()
+	data, err := os.ReadFile(metricsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ms, nil
+		}
+		return ms, err
+	}
+	var stored []storedTier
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return ms, fmt.Errorf("decoding %s: %w", metricsFilePath, err)
+	}
+	for _, st := range stored {
+		for _, t := range ms.tiers {
+			if t.Name == st.Name {
+				t.Points = st.Points
+				if st.Open != nil && t.Step > 0 {
+					t.bucket, t.sum, t.count = st.Open.Start, st.Open.Sum, st.Open.Count
+				}
+			}
+		}
+	}
+	now := time.Now()
+	for _, t := range ms.tiers {
+		t.prune(now)
+	}
+	return ms, nil
+}
+
+func (ms *MetricsStore) save() error {
+	ms.saveMu.Lock()
+	defer ms.saveMu.Unlock()
+
+	ms.mu.Lock()
+	var stored []storedTier
+	for _, t := range ms.tiers {
+		st := storedTier{Name: t.Name, Points: t.Points}
+		if t.count > 0 {
+			st.Open = &storedBucket{Start: t.bucket, Sum: t.sum, Count: t.count}
+		}
+		stored = append(stored, st)
+	}
+	data, err := json.Marshal(stored)
+	ms.lastSaved = time.Now()
+	ms.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metricsFilePath, data, 0644)
+}
+
+// record is subscribed to the sampler. The store is written to disk once a minute.
+func (ms *MetricsStore) record(sample SystemSample) {
+	p := MetricPoint{
+		T:   sample.Time.Unix(),
+		CPU: sample.CPUPercent,
+		Mem: sample.MemPercent,
+	}
+	for _, d := range sample.Disks {
+		if used := 100 - d.FreePercent; used > p.Disk {
+			p.Disk = used
+		}
+	}
+
+	ms.mu.Lock()
+	for _, t := range ms.tiers {
+		t.add(p)
+		t.prune(sample.Time)
+	}
+	due := sample.Time.Sub(ms.lastSaved) >= time.Minute
+	ms.mu.Unlock()
+
+	if due {
+		if err := ms.save(); err != nil {
+			fmt.Println("Error saving metrics:", err)
+		}
+	}
+}
+
+// query returns the points recorded since from, taken from the finest tier
+// whose retention covers span.
+func (ms *MetricsStore) query(from time.Time, span time.Duration) []MetricPoint {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	tier := ms.tiers[len(ms.tiers)-1]
+	for _, t := range ms.tiers {
+		if t.Retention >= span {
+			tier = t
+			break
+		}
+	}
+	cutoff := from.Unix()
+	var res []MetricPoint
+	for _, p := range tier.Points {
+		if p.T >= cutoff {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// ---------------------------------------------------------------------
+//  2) Line Chart Widget
+// ---------------------------------------------------------------------
+
+// LineChart plots a 0-100 percent series over a fixed time window.
+type LineChart struct {
+	widget.BaseWidget
+
+	title    string
+	lineCol  color.Color
+	from, to int64
+	values   []float64
+	times    []int64
+}
+
+func newLineChart(title string, c color.Color) *LineChart {
+	lc := &LineChart{title: title, lineCol: c}
+	lc.ExtendBaseWidget(lc)
+	return lc
+}
+
+func (lc *LineChart) setData(from, to time.Time, times []int64, values []float64) {
+	lc.from, lc.to = from.Unix(), to.Unix()
+	lc.times, lc.values = times, values
+	lc.Refresh()
+}
+
+func (lc *LineChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &lineChartRenderer{
+		chart: lc,
+		bg:    canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground)),
+		title: canvas.NewText(lc.title, theme.Color(theme.ColorNameForeground)),
+		start: canvas.NewText("", theme.Color(theme.ColorNameDisabled)),
+		end:   canvas.NewText("", theme.Color(theme.ColorNameDisabled)),
+	}
+	r.title.TextStyle = fyne.TextStyle{Bold: true}
+	r.start.TextSize = theme.CaptionTextSize()
+	r.end.TextSize = theme.CaptionTextSize()
+	return r
+}
+
+type lineChartRenderer struct {
+	chart *LineChart
+	bg    *canvas.Rectangle
+	title *canvas.Text
+	start *canvas.Text
+	end   *canvas.Text
+	lines []fyne.CanvasObject
+	size  fyne.Size
+}
+
+func (r *lineChartRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.rebuild()
+}
+
+func (r *lineChartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(300, 120)
+}
+
+func (r *lineChartRenderer) Refresh() {
+	r.bg.FillColor = theme.Color(theme.ColorNameInputBackground)
+	r.title.Color = theme.Color(theme.ColorNameForeground)
+	r.rebuild()
+	canvas.Refresh(r.chart)
+}
+
+func (r *lineChartRenderer) Objects() []fyne.CanvasObject {
+	objs := []fyne.CanvasObject{r.bg, r.title, r.start, r.end}
+	return append(objs, r.lines...)
+}
+
+func (r *lineChartRenderer) Destroy() {}
+
+func (r *lineChartRenderer) rebuild() {
+	lc := r.chart
+	size := r.size
+	pad := theme.Padding()
+	titleH := r.title.MinSize().Height
+	axisH := r.start.MinSize().Height
+
+	r.bg.Move(fyne.NewPos(0, titleH))
+	r.bg.Resize(fyne.NewSize(size.Width, size.Height-titleH-axisH))
+	r.title.Move(fyne.NewPos(0, 0))
+
+	latest := ""
+	if n := len(lc.values); n > 0 {
+		latest = fmt.Sprintf(" (now %.1f%%)", lc.values[n-1])
+	}
+	r.title.Text = lc.title + latest
+
+	layoutFmt := "15:04"
+	if lc.to-lc.from > 24*3600 {
+		layoutFmt = "Jan 2 15:04"
+	}
+	if lc.to > 0 {
+		r.start.Text = time.Unix(lc.from, 0).Format(layoutFmt)
+		r.end.Text = time.Unix(lc.to, 0).Format(layoutFmt)
+	}
+	r.start.Move(fyne.NewPos(0, size.Height-axisH))
+	r.end.Move(fyne.NewPos(size.Width-r.end.MinSize().Width, size.Height-axisH))
+
+	r.lines = nil
+	plotW := size.Width - 2*pad
+	plotH := size.Height - titleH - axisH - 2*pad
+	if plotW <= 0 || plotH <= 0 || lc.to <= lc.from {
+		return
+	}
+	span := float32(lc.to - lc.from)
+	pointAt := func(i int) fyne.Position {
+		x := pad + plotW*float32(lc.times[i]-lc.from)/span
+		y := titleH + pad + plotH*(1-float32(lc.values[i])/100)
+		return fyne.NewPos(x, y)
+	}
+	for i := 1; i < len(lc.values); i++ {
+		// leave a gap where the app was not running
+		if lc.times[i]-lc.times[i-1] > int64(span)/20 {
+			continue
+		}
+		l := canvas.NewLine(lc.lineCol)
+		l.StrokeWidth = 1.5
+		l.Position1 = pointAt(i - 1)
+		l.Position2 = pointAt(i)
+		r.lines = append(r.lines, l)
+	}
+}
+
+// ---------------------------------------------------------------------
+//  3) History UI
+// ---------------------------------------------------------------------
+
+var metricRanges = map[string]time.Duration{
+	"Last hour": time.Hour,
+	"Last day":  24 * time.Hour,
+	"Last week": 7 * 24 * time.Hour,
+}
+
+func (s *FileScanner) setupMetricsHistoryUI() fyne.CanvasObject {
+	cpuChart := newLineChart("CPU %", color.RGBA{R: 255, G: 165, B: 0, A: 255})
+	memChart := newLineChart("Memory %", color.RGBA{R: 80, G: 160, B: 255, A: 255})
+	diskChart := newLineChart("Disk used % (fullest volume)", color.RGBA{R: 120, G: 200, B: 120, A: 255})
+
+	selected := "Last hour"
+	refresh := func() {
+		now := time.Now()
+		span := metricRanges[selected]
+		from := now.Add(-span)
+		points := s.metrics.query(from, span)
+		times := make([]int64, len(points))
+		cpuVals := make([]float64, len(points))
+		memVals := make([]float64, len(points))
+		diskVals := make([]float64, len(points))
+		for i, p := range points {
+			times[i] = p.T
+			cpuVals[i], memVals[i], diskVals[i] = p.CPU, p.Mem, p.Disk
+		}
+		cpuChart.setData(from, now, times, cpuVals)
+		memChart.setData(from, now, times, memVals)
+		diskChart.setData(from, now, times, diskVals)
+	}
+
+	rangeSelect := widget.NewSelect([]string{"Last hour", "Last day", "Last week"}, func(val string) {
+		selected = val
+		refresh()
+	})
+	rangeSelect.SetSelected(selected)
+
+	refreshBtn := s.commandButton(toolSystemInfo, "Refresh", nil, refresh)
+
+	topBar := container.NewHBox(widget.NewLabel("Range:"), rangeSelect, refreshBtn, layout.NewSpacer())
+	charts := container.NewGridWithRows(3, cpuChart, memChart, diskChart)
+	refresh()
+
+	return container.NewBorder(topBar, nil, nil, nil, charts)
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const plansDir = "deletion_plans" // Executed plans are archived here
+
+// ---------------------------------------------------------------------
+//  1) Deletion Plans
+// ---------------------------------------------------------------------
+
+// PlanEntry is one file a plan would delete. Size and ModTime are recorded
+// so that executing the plan later only touches the exact same files.
+type PlanEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Reason  string
+	Include bool
+}
+
+// DeletionPlan is the result of a dry run. It can be saved, edited and
+// executed later; the resulting DeletionRecords carry its ID.
+type DeletionPlan struct {
+	ID       string
+	Created  string
+	Method   string // "Duplicate Finder" or "Space Cleaner"
+	Hash     string `json:",omitempty"` // how Duplicate Finder groups were found, e.g. "SHA-256"
+	Verify   bool   `json:",omitempty"` // compare Duplicate Finder files byte for byte before deleting
+	Entries  []PlanEntry
+	Executed string `json:",omitempty"`
+
+	// verify, if set, is called with the paths about to be deleted and
+	// returns those that may go, plus why the others may not
+	verify func(paths []string) (ok []string, problems []string)
+}
+
+// newDeletionPlan starts an empty plan. The ID ends in random hex digits,
+// so plans made within the same second do not share it.
+func newDeletionPlan(method string) *DeletionPlan {
+	now := time.Now()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return &DeletionPlan{
+		ID:      "plan-" + now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix),
+		Created: now.Format("2006-01-02 15:04:05"),
+		Method:  method,
+	}
+}
+
+// addFile records path with its current size (a folder's total size) and
+// modification time. Files blocked by the protection policy are kept in the
+// plan but excluded.
+func (p *DeletionPlan) addFile(path, reason string, pp *ProtectionPolicy) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	size := st.Size()
+	if st.IsDir() {
+		size = treeSize(path)
+	}
+	e := PlanEntry{Path: path, Size: size, ModTime: st.ModTime(), Reason: reason, Include: true}
+	switch v := pp.check(path); v.Level {
+	case protectBlock:
+		e.Include = false
+		e.Reason += " — blocked: " + v.Reason
+	case protectWarn:
+		e.Reason += " — warning: " + v.Reason
+	}
+	p.Entries = append(p.Entries, e)
+}
+
+// reclaimable returns the number and total size of included entries.
+func (p *DeletionPlan) reclaimable() (int, int64) {
+	var n int
+	var total int64
+	for _, e := range p.Entries {
+		if e.Include {
+			n++
+			total += e.Size
+		}
+	}
+	return n, total
+}
+
+func (p *DeletionPlan) includedPaths() []string {
+	var res []string
+	for _, e := range p.Entries {
+		if e.Include {
+			res = append(res, e.Path)
+		}
+	}
+	return res
+}
+
+func loadDeletionPlan(data []byte) (*DeletionPlan, error) {
+	var p DeletionPlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.ID == "" {
+		return nil, fmt.Errorf("not a deletion plan")
+	}
+	return &p, nil
+}
+
+func (p *DeletionPlan) archive() error {
+	if err := os.MkdirAll(plansDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(plansDir, p.ID+".json"), data, 0644)
+}
+
+// executePlan deletes the allowed entries that have not changed since the
+// plan was made and returns the paths that were deleted. Results are shown
+// over parent.
+func (s *FileScanner) executePlan(p *DeletionPlan, allowed []string, parent fyne.Window) []string {
+	var deleted []string
+	var errs []string
+	if p.verify != nil {
+		var problems []string
+		allowed, problems = p.verify(allowed)
+		if len(allowed) == 0 && len(problems) > 0 {
+			// refused as a whole; the plan stays open to run again
+			dialog.ShowError(errors.New(strings.Join(problems, "\n")), parent)
+			return nil
+		}
+		errs = append(errs, problems...)
+	}
+	ok := make(map[string]bool)
+	for _, a := range allowed {
+		ok[a] = true
+	}
+
+	for _, e := range p.Entries {
+		if !e.Include || !ok[e.Path] {
+			continue
+		}
+		st, err := os.Stat(e.Path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Skipped %s: %v", e.Path, err))
+			continue
+		}
+		size := st.Size()
+		if st.IsDir() {
+			size = treeSize(e.Path)
+		}
+		if size != e.Size || !st.ModTime().Equal(e.ModTime) {
+			errs = append(errs, fmt.Sprintf("Skipped %s: changed since the plan was made", e.Path))
+			continue
+		}
+		if err := removePath(e.Path); err != nil {
+			errs = append(errs, fmt.Sprintf("Failed to delete %s: %v", e.Path, err))
+			continue
+		}
+		deleted = append(deleted, e.Path)
+		s.addDeletionRecordWithPlan(e.Path, p.Method, p.ID)
+	}
+
+	p.Executed = time.Now().Format("2006-01-02 15:04:05")
+	if err := p.archive(); err != nil {
+		errs = append(errs, fmt.Sprintf("Failed to archive plan: %v", err))
+	}
+	if len(errs) > 0 {
+		dialog.ShowError(errors.New(strings.Join(errs, "\n")), parent)
+	}
+	dialog.ShowInformation("Plan Executed", fmt.Sprintf("Deleted %d file(s) from %s.", len(deleted), p.ID), parent)
+	s.refreshDeletionTable()
+	return deleted
+}
+
+// duplicatePlan builds a plan for the checked Duplicate Finder files. Each
+// reason names a copy that is kept. verify asks for verifyDuplicates to run
+// when the plan is executed.
+func (s *FileScanner) duplicatePlan(paths []string, verify bool) *DeletionPlan {
+	selected := make(map[string]bool)
+	for _, p := range paths {
+		selected[p] = true
+	}
+	groupOf := make(map[string][]string)
+	relation := make(map[string]string) // how a file relates to the rest of its group
+	s.mu.Lock()
+	hash := s.dfAlgorithm
+	if s.dfVerified {
+		hash += ", verified with " + algoSHA256
+	}
+	for key, group := range s.allDuplicates {
+		rel := "duplicate of "
+		switch {
+		case strings.HasPrefix(key, similarGroupPrefix):
+			rel = "similar to "
+		case strings.HasPrefix(key, mediaGroupPrefix):
+			rel = "same recording as "
+		case strings.HasPrefix(key, folderGroupPrefix):
+			rel = "duplicate folder of "
+		}
+		for _, g := range group {
+			groupOf[g] = group
+			relation[g] = rel
+		}
+	}
+	s.mu.Unlock()
+
+	pp := s.protectionPolicy()
+	plan := newDeletionPlan("Duplicate Finder")
+	plan.Hash = hash
+	plan.Verify = verify
+	s.attachPlanChecks(plan)
+	for _, p := range paths {
+		reason := "duplicate"
+		var other string
+		for _, g := range groupOf[p] {
+			if g == p {
+				continue
+			}
+			if other == "" {
+				other = g
+			}
+			if !selected[g] {
+				other = g
+				break
+			}
+		}
+		if other != "" {
+			reason = relation[p] + other
+			if selected[other] {
+				reason += " (every copy is selected)"
+			}
+		}
+		plan.addFile(p, reason, pp)
+	}
+	return plan
+}
+
+// attachPlanChecks sets the checks run before a plan's files are deleted,
+// for new plans and plans loaded from a file alike. Duplicate Finder plans
+// always keep a copy of each group of the current scan.
+func (s *FileScanner) attachPlanChecks(p *DeletionPlan) {
+	if p.Method != "Duplicate Finder" {
+		return
+	}
+	p.verify = s.keepCopies
+	if p.Verify {
+		p.verify = s.verifyDuplicates
+	}
+}
+
+// spaceCleanerPlan builds a plan for the checked Space Cleaner files.
+func (s *FileScanner) spaceCleanerPlan(paths []string) *DeletionPlan {
+	category := make(map[string]string)
+	s.mu.Lock()
+	for _, lf := range s.largeFileItems {
+		category[lf.filePath] = lf.category
+	}
+	s.mu.Unlock()
+
+	pp := newProtectionPolicy()
+	plan := newDeletionPlan("Space Cleaner")
+	for _, p := range paths {
+		reason := "large file"
+		if c := category[p]; c != "" {
+			reason += " (" + c + ")"
+		}
+		if st, err := os.Stat(p); err == nil {
+			reason += ", modified " + st.ModTime().Format("2006-01-02")
+		}
+		plan.addFile(p, reason, pp)
+	}
+	return plan
+}
+
+// ---------------------------------------------------------------------
+//  2) Plan Review UI
+// ---------------------------------------------------------------------
+
+// showPlanReview lets the user untick entries, save the plan, or execute it.
+// onExecuted receives the deleted paths.
+func (s *FileScanner) showPlanReview(p *DeletionPlan, onExecuted func(deleted []string)) {
+	summary := widget.NewLabel("")
+	updateSummary := func() {
+		n, total := p.reclaimable()
+		method := p.Method
+		if p.Hash != "" {
+			method += ", " + p.Hash
+		}
+		text := fmt.Sprintf("%s (%s) — %d of %d file(s), %s reclaimable", p.ID, method, n, len(p.Entries), formatSize(total))
+		if p.Executed != "" {
+			text += " — executed " + p.Executed
+		}
+		summary.SetText(text)
+	}
+
+	list := widget.NewList(
+		func() int { return len(p.Entries) },
+		func() fyne.CanvasObject {
+			return widget.NewCheck("", nil)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			e := &p.Entries[id]
+			chk := obj.(*widget.Check)
+			chk.OnChanged = nil
+			chk.Text = fmt.Sprintf("%s (%s) — %s", e.Path, formatSize(e.Size), e.Reason)
+			chk.SetChecked(e.Include)
+			chk.OnChanged = func(b bool) {
+				e.Include = b
+				updateSummary()
+			}
+		},
+	)
+
+	var win fyne.Window
+	saveBtn := widget.NewButton("Save Plan", func() {
+		dialog.ShowFileSave(func(write fyne.URIWriteCloser, err error) {
+			if err != nil || write == nil {
+				return
+			}
+			defer write.Close()
+			data, err := json.MarshalIndent(p, "", "  ")
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			write.Write(data)
+			dialog.ShowInformation("Saved", "Deletion plan saved.", win)
+		}, win)
+	})
+	executeBtn := widget.NewButton("Execute Plan", func() {
+		if p.Executed != "" {
+			dialog.ShowInformation("Already Executed", "This plan was executed on "+p.Executed+".", win)
+			return
+		}
+		paths := p.includedPaths()
+		if len(paths) == 0 {
+			dialog.ShowInformation("Nothing To Delete", "No files are included in the plan.", win)
+			return
+		}
+		s.confirmGuardedIn(win, "Execute Plan", "Delete", paths, func(allowed []string) {
+			deleted := s.executePlan(p, allowed, win)
+			updateSummary()
+			if onExecuted != nil {
+				onExecuted(deleted)
+			}
+			// the window stays open; the results are shown over it
+		})
+	})
+	closeBtn := widget.NewButton("Close", func() {
+		win.Close()
+	})
+
+	updateSummary()
+	content := container.NewBorder(
+		summary,
+		container.NewHBox(saveBtn, executeBtn, layout.NewSpacer(), closeBtn),
+		nil, nil,
+		list,
+	)
+
+	win = fyne.CurrentApp().NewWindow("Deletion Plan — " + p.ID)
+	win.SetContent(content)
+	win.Resize(fyne.NewSize(1000, 600))
+	win.Show()
+}
+
+// openPlanDialog loads a saved plan for review and execution.
+func (s *FileScanner) openPlanDialog() {
+	dialog.ShowFileOpen(func(read fyne.URIReadCloser, err error) {
+		if err != nil || read == nil {
+			return
+		}
+		defer read.Close()
+		data, err := io.ReadAll(read)
+		if err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return
+		}
+		p, err := loadDeletionPlan(data)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("invalid deletion plan: %v", err), s.mainWindow)
+			return
+		}
+		s.attachPlanChecks(p)
+		s.showPlanReview(p, nil)
+	}, s.mainWindow)
+}
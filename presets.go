@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const filterPresetsFilePath = "filter_presets.json" // Filter presets saved from the Duplicate Finder and Space Cleaner
+
+// FilterPreset is a named set of filter rules.
+type FilterPreset struct {
+	Name   string
+	Filter FileFilter
+}
+
+// builtinFilterPresets are always offered and cannot be deleted. Unlike
+// saved presets, which replace the whole form, they add their rules to it.
+var builtinFilterPresets = []FilterPreset{
+	{"Skip version control and dependencies", FileFilter{
+		ExcludeDirs: []string{".git", ".svn", ".hg", "node_modules", "vendor", "__pycache__"},
+	}},
+	{"Skip hidden and system files", FileFilter{SkipHidden: true, SkipSystem: true}},
+}
+
+// ---------------------------------------------------------------------
+//  1) Preset Store
+// ---------------------------------------------------------------------
+
+func loadFilterPresets() ([]FilterPreset, error) {
+	data, err := os.ReadFile(filterPresetsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var presets []FilterPreset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", filterPresetsFilePath, err)
+	}
+	return presets, nil
+}
+
+func saveFilterPresets(presets []FilterPreset) error {
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filterPresetsFilePath, data, 0644)
+}
+
+func isBuiltinPreset(name string) bool {
+	for _, p := range builtinFilterPresets {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------------------------------------------------------------
+//  2) Filter Form
+// ---------------------------------------------------------------------
+
+// filterForm edits a FileFilter. The Duplicate Finder and the Space Cleaner
+// each have one; presets saved in either are offered in both. Extensions
+// are not part of the form, the Duplicate Finder has its own entry for them.
+type filterForm struct {
+	s *FileScanner
+
+	minSize, maxSize              *widget.Entry
+	notModified, notAccessed      *widget.Entry
+	modifiedAfter, modifiedBefore *widget.Entry
+	include, exclude, skipDir     *widget.Entry
+	skipHidden, skipSystem        *widget.Check
+	presetSelect                  *widget.Select
+
+	presets []FilterPreset // user presets
+}
+
+func (s *FileScanner) newFilterForm(initial FileFilter) *filterForm {
+	ff := &filterForm{
+		s:              s,
+		minSize:        widget.NewEntry(),
+		maxSize:        widget.NewEntry(),
+		notModified:    widget.NewEntry(),
+		notAccessed:    widget.NewEntry(),
+		modifiedAfter:  widget.NewEntry(),
+		modifiedBefore: widget.NewEntry(),
+		include:        widget.NewEntry(),
+		exclude:        widget.NewEntry(),
+		skipDir:        widget.NewEntry(),
+		skipHidden:     widget.NewCheck("Skip hidden files and folders", nil),
+		skipSystem:     widget.NewCheck("Skip system files and folders", nil),
+	}
+	ff.minSize.SetPlaceHolder("no minimum")
+	ff.maxSize.SetPlaceHolder("no limit")
+	ff.notModified.SetPlaceHolder("any age")
+	ff.notAccessed.SetPlaceHolder("any age")
+	ff.modifiedAfter.SetPlaceHolder(dateLayout)
+	ff.modifiedBefore.SetPlaceHolder(dateLayout)
+	ff.include.SetPlaceHolder("e.g. *.iso,*.zip,re:backup_\\d+")
+	ff.exclude.SetPlaceHolder("e.g. *.dll,*/cache/*")
+	ff.skipDir.SetPlaceHolder("e.g. .git,node_modules")
+
+	presets, err := loadFilterPresets()
+	if err != nil {
+		fmt.Println("Error loading filter presets:", err)
+	}
+	ff.presets = presets
+	ff.presetSelect = widget.NewSelect(nil, func(name string) {
+		p, ok := ff.preset(name)
+		if !ok {
+			return
+		}
+		if !isBuiltinPreset(name) {
+			ff.show(p.Filter)
+			return
+		}
+		cur, err := ff.read()
+		if err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return
+		}
+		for _, d := range p.Filter.ExcludeDirs {
+			if !contains(cur.ExcludeDirs, d) {
+				cur.ExcludeDirs = append(cur.ExcludeDirs, d)
+			}
+		}
+		cur.SkipHidden = cur.SkipHidden || p.Filter.SkipHidden
+		cur.SkipSystem = cur.SkipSystem || p.Filter.SkipSystem
+		ff.show(cur)
+	})
+	ff.presetSelect.PlaceHolder = "Presets"
+	ff.refreshPresets()
+	ff.show(initial)
+	s.filterForms = append(s.filterForms, ff)
+	return ff
+}
+
+func (ff *filterForm) preset(name string) (FilterPreset, bool) {
+	for _, p := range append(append([]FilterPreset{}, builtinFilterPresets...), ff.presets...) {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return FilterPreset{}, false
+}
+
+// setPresets updates the user presets of every filter form.
+func (ff *filterForm) setPresets(presets []FilterPreset) {
+	for _, other := range ff.s.filterForms {
+		other.presets = presets
+		other.refreshPresets()
+	}
+}
+
+func (ff *filterForm) refreshPresets() {
+	var names []string
+	for _, p := range builtinFilterPresets {
+		names = append(names, p.Name)
+	}
+	for _, p := range ff.presets {
+		names = append(names, p.Name)
+	}
+	ff.presetSelect.Options = names
+	ff.presetSelect.Refresh()
+}
+
+// show fills the form with f.
+func (ff *filterForm) show(f FileFilter) {
+	mb := func(b int64) string {
+		if b == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(float64(b)/1048576, 'f', -1, 64)
+	}
+	days := func(d int) string {
+		if d == 0 {
+			return ""
+		}
+		return strconv.Itoa(d)
+	}
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(dateLayout)
+	}
+	ff.minSize.SetText(mb(f.MinSize))
+	ff.maxSize.SetText(mb(f.MaxSize))
+	ff.notModified.SetText(days(f.NotModifiedDays))
+	ff.notAccessed.SetText(days(f.NotAccessedDays))
+	ff.modifiedAfter.SetText(date(f.ModifiedAfter))
+	ff.modifiedBefore.SetText(date(f.ModifiedBefore))
+	ff.include.SetText(strings.Join(f.IncludePatterns, ","))
+	ff.exclude.SetText(strings.Join(f.ExcludePatterns, ","))
+	ff.skipDir.SetText(strings.Join(f.ExcludeDirs, ","))
+	ff.skipHidden.SetChecked(f.SkipHidden)
+	ff.skipSystem.SetChecked(f.SkipSystem)
+}
+
+// read parses the form.
+func (ff *filterForm) read() (FileFilter, error) {
+	f, err := parseFileFilter(ff.minSize.Text, ff.notModified.Text, ff.notAccessed.Text, ff.include.Text, ff.exclude.Text)
+	if err != nil {
+		return f, err
+	}
+	if f.MaxSize, err = parseSizeMB(ff.maxSize.Text, "maximum"); err != nil {
+		return f, err
+	}
+	if f.MaxSize > 0 && f.MaxSize < f.MinSize {
+		return f, fmt.Errorf("maximum size is below the minimum size")
+	}
+	if f.ModifiedAfter, err = parseDate(ff.modifiedAfter.Text, "modified after"); err != nil {
+		return f, err
+	}
+	if f.ModifiedBefore, err = parseDate(ff.modifiedBefore.Text, "modified before"); err != nil {
+		return f, err
+	}
+	if !f.ModifiedAfter.IsZero() && !f.ModifiedBefore.IsZero() && f.ModifiedBefore.Before(f.ModifiedAfter) {
+		return f, fmt.Errorf("modified before date is earlier than the modified after date")
+	}
+	f.ExcludeDirs = splitPatterns(ff.skipDir.Text)
+	for _, d := range f.ExcludeDirs {
+		if _, err := filepath.Match(d, ""); err != nil {
+			return f, fmt.Errorf("invalid folder pattern %q", d)
+		}
+	}
+	f.SkipHidden = ff.skipHidden.Checked
+	f.SkipSystem = ff.skipSystem.Checked
+	return f, nil
+}
+
+func (ff *filterForm) savePreset() {
+	f, err := ff.read()
+	if err != nil {
+		dialog.ShowError(err, ff.s.mainWindow)
+		return
+	}
+	name := widget.NewEntry()
+	dialog.ShowForm("Save Filter Preset", "Save", "Cancel", []*widget.FormItem{widget.NewFormItem("Name", name)}, func(ok bool) {
+		n := strings.TrimSpace(name.Text)
+		if !ok || n == "" {
+			return
+		}
+		if isBuiltinPreset(n) {
+			dialog.ShowError(fmt.Errorf("%q is a built-in preset", n), ff.s.mainWindow)
+			return
+		}
+		// reload, the other tool's form may have saved presets meanwhile
+		presets, err := loadFilterPresets()
+		if err != nil {
+			dialog.ShowError(err, ff.s.mainWindow)
+			return
+		}
+		replaced := false
+		for i := range presets {
+			if presets[i].Name == n {
+				presets[i].Filter, replaced = f, true
+			}
+		}
+		if !replaced {
+			presets = append(presets, FilterPreset{Name: n, Filter: f})
+		}
+		if err := saveFilterPresets(presets); err != nil {
+			dialog.ShowError(err, ff.s.mainWindow)
+			return
+		}
+		ff.setPresets(presets)
+		ff.presetSelect.SetSelected(n)
+	}, ff.s.mainWindow)
+}
+
+func (ff *filterForm) deletePreset() {
+	n := ff.presetSelect.Selected
+	if n == "" || isBuiltinPreset(n) {
+		dialog.ShowInformation("Delete Preset", "Select a preset you saved.", ff.s.mainWindow)
+		return
+	}
+	presets, err := loadFilterPresets()
+	if err != nil {
+		dialog.ShowError(err, ff.s.mainWindow)
+		return
+	}
+	var kept []FilterPreset
+	for _, p := range presets {
+		if p.Name != n {
+			kept = append(kept, p)
+		}
+	}
+	if err := saveFilterPresets(kept); err != nil {
+		dialog.ShowError(err, ff.s.mainWindow)
+		return
+	}
+	ff.presetSelect.ClearSelected()
+	ff.setPresets(kept)
+}
+
+func (ff *filterForm) content() fyne.CanvasObject {
+	form := widget.NewForm(
+		widget.NewFormItem("Minimum size (MB)", ff.minSize),
+		widget.NewFormItem("Maximum size (MB)", ff.maxSize),
+		widget.NewFormItem("Not modified in (days)", ff.notModified),
+		widget.NewFormItem("Not accessed in (days)", ff.notAccessed),
+		widget.NewFormItem("Modified after", ff.modifiedAfter),
+		widget.NewFormItem("Modified before", ff.modifiedBefore),
+		widget.NewFormItem("Include", ff.include),
+		widget.NewFormItem("Exclude", ff.exclude),
+		widget.NewFormItem("Skip folders", ff.skipDir),
+	)
+	presets := container.NewHBox(ff.presetSelect,
+		widget.NewButton("Save Preset...", ff.savePreset),
+		widget.NewButton("Delete Preset", ff.deletePreset),
+	)
+	help := widget.NewLabel("Patterns are comma-separated globs; prefix one with re: for a regular expression on the full path.")
+	help.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(presets, form, container.NewHBox(ff.skipHidden, ff.skipSystem), help)
+}
+
+// summary describes f in a few words for a label.
+func (f FileFilter) summary() string {
+	var parts []string
+	if f.MinSize > 0 || f.MaxSize > 0 {
+		parts = append(parts, "size")
+	}
+	if f.NotModifiedDays > 0 || f.NotAccessedDays > 0 || !f.ModifiedAfter.IsZero() || !f.ModifiedBefore.IsZero() {
+		parts = append(parts, "dates")
+	}
+	if len(f.IncludePatterns)+len(f.ExcludePatterns) > 0 {
+		parts = append(parts, "patterns")
+	}
+	if len(f.ExcludeDirs) > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped folder(s)", len(f.ExcludeDirs)))
+	}
+	if f.SkipHidden {
+		parts = append(parts, "no hidden")
+	}
+	if f.SkipSystem {
+		parts = append(parts, "no system")
+	}
+	if len(parts) == 0 {
+		return "No filters"
+	}
+	return "Filters: " + strings.Join(parts, ", ")
+}
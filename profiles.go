@@ -0,0 +1,228 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const userProfilesDir = "cleanup_profiles" // Directory for user-added cleanup profiles
+
+//go:embed profiles/*.json
+var builtinProfileFS embed.FS
+
+// ---------------------------------------------------------------------
+//  1) Cleanup Profiles
+// ---------------------------------------------------------------------
+
+const (
+	safetySafe    = "safe"    // caches and temp files that are recreated on demand
+	safetyReview  = "review"  // user data that is usually disposable
+	safetyCaution = "caution" // application or system files
+)
+
+// CleanupTarget is one directory the Space Cleaner can scan. Path may use
+// %VAR%, $VAR, ${VAR} and a leading ~. The optional rules override the
+// filters entered in the Space Cleaner for this target only.
+type CleanupTarget struct {
+	Name            string
+	Path            string
+	Safety          string
+	MinSizeMB       float64  `json:",omitempty"`
+	NotModifiedDays int      `json:",omitempty"`
+	NotAccessedDays int      `json:",omitempty"`
+	Include         []string `json:",omitempty"`
+	Exclude         []string `json:",omitempty"`
+}
+
+// CleanupProfile is a named set of targets, usually for one platform.
+type CleanupProfile struct {
+	Name     string
+	Platform string // runtime.GOOS value, or empty for any
+	Targets  []CleanupTarget
+}
+
+func parseCleanupProfile(data []byte) (CleanupProfile, error) {
+	var p CleanupProfile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		return p, fmt.Errorf("profile has no name")
+	}
+	for i, t := range p.Targets {
+		if strings.TrimSpace(t.Path) == "" {
+			return p, fmt.Errorf("target %d (%s) has no path", i+1, t.Name)
+		}
+		switch t.Safety {
+		case safetySafe, safetyReview, safetyCaution:
+		case "":
+			p.Targets[i].Safety = safetyReview
+		default:
+			return p, fmt.Errorf("target %s has unknown safety level %q", t.Name, t.Safety)
+		}
+		for _, pat := range append(append([]string{}, t.Include...), t.Exclude...) {
+			if err := validatePattern(pat); err != nil {
+				return p, fmt.Errorf("target %s: %v", t.Name, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// loadCleanupProfiles returns the built-in profiles followed by the user's.
+// Broken user profiles are skipped and reported.
+func loadCleanupProfiles() ([]CleanupProfile, []error) {
+	var profiles []CleanupProfile
+	var errs []error
+
+	builtin, _ := builtinProfileFS.ReadDir("profiles")
+	for _, e := range builtin {
+		data, err := builtinProfileFS.ReadFile("profiles/" + e.Name())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p, err := parseCleanupProfile(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name(), err))
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+
+	userFiles, _ := filepath.Glob(filepath.Join(userProfilesDir, "*.json"))
+	for _, f := range userFiles {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p, err := parseCleanupProfile(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f, err))
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, errs
+}
+
+// importCleanupProfile validates a profile file and copies it into userProfilesDir.
+func importCleanupProfile(data []byte) (CleanupProfile, error) {
+	p, err := parseCleanupProfile(data)
+	if err != nil {
+		return p, err
+	}
+	if err := os.MkdirAll(userProfilesDir, 0755); err != nil {
+		return p, err
+	}
+	name := regexp.MustCompile(`[^A-Za-z0-9_-]+`).ReplaceAllString(p.Name, "_")
+	return p, os.WriteFile(filepath.Join(userProfilesDir, name+".json"), data, 0644)
+}
+
+// cautionDirs returns the expanded paths of the caution targets in the
+// profiles for this platform. Scheduled jobs only quarantine or delete
+// files inside them when told to.
+func cautionDirs() []string {
+	profiles, _ := loadCleanupProfiles()
+	var dirs []string
+	for _, p := range profiles {
+		if p.Platform != "" && p.Platform != runtime.GOOS {
+			continue
+		}
+		for _, t := range p.Targets {
+			if t.Safety == safetyCaution {
+				dirs = append(dirs, expandPathTemplate(t.Path))
+			}
+		}
+	}
+	return dirs
+}
+
+// defaultProfileIndex picks the first profile made for this platform.
+func defaultProfileIndex(profiles []CleanupProfile) int {
+	for i, p := range profiles {
+		if p.Platform == runtime.GOOS {
+			return i
+		}
+	}
+	return 0
+}
+
+// ---------------------------------------------------------------------
+//  2) Path Templates
+// ---------------------------------------------------------------------
+
+var windowsEnvVar = regexp.MustCompile(`%([A-Za-z0-9_()]+)%`)
+
+// envDefaults fills in well-known variables that are often unset.
+func envDefaults(name string) string {
+	home, _ := os.UserHomeDir()
+	switch name {
+	case "USERPROFILE", "HOME":
+		return home
+	case "LOCALAPPDATA":
+		return filepath.Join(home, "AppData", "Local")
+	case "APPDATA":
+		return filepath.Join(home, "AppData", "Roaming")
+	case "TEMP", "TMP":
+		return os.TempDir()
+	case "SystemRoot", "SYSTEMROOT", "windir":
+		return `C:\Windows`
+	case "ProgramFiles":
+		return `C:\Program Files`
+	case "XDG_CACHE_HOME":
+		return filepath.Join(home, ".cache")
+	case "XDG_DATA_HOME":
+		return filepath.Join(home, ".local", "share")
+	case "XDG_CONFIG_HOME":
+		return filepath.Join(home, ".config")
+	case "XDG_DOWNLOAD_DIR":
+		return filepath.Join(home, "Downloads")
+	}
+	return ""
+}
+
+func lookupEnv(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return envDefaults(name)
+}
+
+// expandPathTemplate expands environment variables and ~ in a target path.
+func expandPathTemplate(tmpl string) string {
+	p := windowsEnvVar.ReplaceAllStringFunc(tmpl, func(m string) string {
+		return lookupEnv(m[1 : len(m)-1])
+	})
+	p = os.Expand(p, lookupEnv)
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		home, _ := os.UserHomeDir()
+		p = home + p[1:]
+	}
+	return filepath.Clean(p)
+}
+
+// withTargetRules returns f with the target's own rules taking precedence.
+func (f FileFilter) withTargetRules(t CleanupTarget) FileFilter {
+	if t.MinSizeMB > 0 {
+		f.MinSize = int64(t.MinSizeMB * 1048576)
+	}
+	if t.NotModifiedDays > 0 {
+		f.NotModifiedDays = t.NotModifiedDays
+	}
+	if t.NotAccessedDays > 0 {
+		f.NotAccessedDays = t.NotAccessedDays
+	}
+	if len(t.Include) > 0 {
+		f.IncludePatterns = t.Include
+	}
+	f.ExcludePatterns = append(append([]string{}, f.ExcludePatterns...), t.Exclude...)
+	return f
+}
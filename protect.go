@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const protectedPathsFilePath = "protected_paths.json" // User-defined paths that are never deleted
+
+// ---------------------------------------------------------------------
+//  1) Protection Policy
+// ---------------------------------------------------------------------
+
+type ProtectionLevel int
+
+const (
+	protectNone  ProtectionLevel = iota
+	protectWarn                  // allowed after the user has seen the reason
+	protectBlock                 // never deleted or renamed
+)
+
+type ProtectionVerdict struct {
+	Path   string
+	Level  ProtectionLevel
+	Reason string
+}
+
+// ProtectionPolicy is consulted before every delete or rename. It is built
+// right before an operation so the running-process snapshot is current.
+type ProtectionPolicy struct {
+	systemDirs    []string
+	cleanableDirs []string // inside systemDirs, but their contents may go
+	appDataDirs   []string
+	userProtected []string
+	referenceDirs []string        // Duplicate Finder reference folders
+	running       map[string]bool // executables of running processes
+	inUse         func(path string) bool
+}
+
+func systemDirectories() []string {
+	if runtime.GOOS == "windows" {
+		dirs := []string{
+			lookupEnv("SystemRoot"),
+			lookupEnv("ProgramFiles"),
+			os.Getenv("ProgramFiles(x86)"),
+			os.Getenv("ProgramData"),
+		}
+		var res []string
+		for _, d := range dirs {
+			if d != "" {
+				res = append(res, d)
+			}
+		}
+		return res
+	}
+	return []string{"/bin", "/boot", "/dev", "/etc", "/lib", "/lib32", "/lib64", "/proc",
+		"/sbin", "/sys", "/usr", "/var/lib", "/System", "/Library", "/Applications"}
+}
+
+// cleanableSystemDirectories are folders inside the system directories that
+// only hold disposable files, such as the Windows temp folder. Their contents
+// get the ordinary checks; the folders themselves stay blocked.
+func cleanableSystemDirectories() []string {
+	if runtime.GOOS == "windows" {
+		root := lookupEnv("SystemRoot")
+		if root == "" {
+			return nil
+		}
+		return []string{
+			filepath.Join(root, "Temp"),
+			filepath.Join(root, "SoftwareDistribution", "Download"),
+		}
+	}
+	return nil
+}
+
+func appDataDirectories() []string {
+	if runtime.GOOS == "windows" {
+		return []string{lookupEnv("LOCALAPPDATA"), lookupEnv("APPDATA")}
+	}
+	return []string{lookupEnv("XDG_CONFIG_HOME"), lookupEnv("XDG_DATA_HOME")}
+}
+
+var executableExtensions = map[string]bool{
+	".exe": true, ".dll": true, ".sys": true, ".com": true, ".scr": true,
+	".so": true, ".dylib": true, ".ko": true,
+}
+
+func loadProtectedPaths() ([]string, error) {
+	data, err := os.ReadFile(protectedPathsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", protectedPathsFilePath, err)
+	}
+	return paths, nil
+}
+
+func saveProtectedPaths(paths []string) error {
+	data, err := json.MarshalIndent(paths, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(protectedPathsFilePath, data, 0644)
+}
+
+func newProtectionPolicy() *ProtectionPolicy {
+	pp := &ProtectionPolicy{
+		systemDirs:    systemDirectories(),
+		cleanableDirs: cleanableSystemDirectories(),
+		appDataDirs:   appDataDirectories(),
+		running:       map[string]bool{},
+		inUse:         newInUseChecker(),
+	}
+	if paths, err := loadProtectedPaths(); err == nil {
+		for _, p := range paths {
+			pp.userProtected = append(pp.userProtected, expandPathTemplate(p))
+		}
+	} else {
+		fmt.Println("Error loading protected paths:", err)
+	}
+
+	procs, _ := process.Processes()
+	for _, p := range procs {
+		if exe, err := p.Exe(); err == nil && exe != "" {
+			pp.running[normalizePath(exe)] = true
+		}
+	}
+	return pp
+}
+
+// normalizePath makes paths comparable (case-insensitive on Windows).
+func normalizePath(p string) string {
+	p = filepath.Clean(p)
+	if runtime.GOOS == "windows" {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+// isUnder reports whether path is dir or inside it.
+func isUnder(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	path, dir = normalizePath(path), normalizePath(dir)
+	if path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// underAny returns the first of dirs that holds path.
+func underAny(dirs []string, path string) (string, bool) {
+	for _, d := range dirs {
+		if isUnder(path, d) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+func (pp *ProtectionPolicy) check(path string) ProtectionVerdict {
+	v := ProtectionVerdict{Path: path}
+	for _, d := range pp.userProtected {
+		if isUnder(path, d) {
+			v.Level, v.Reason = protectBlock, "protected path "+d
+			return v
+		}
+	}
+	for _, d := range pp.referenceDirs {
+		if isUnder(path, d) {
+			v.Level, v.Reason = protectBlock, "reference folder "+d
+			return v
+		}
+	}
+	for _, d := range pp.systemDirs {
+		if isUnder(path, d) && !pp.cleanable(path) {
+			v.Level, v.Reason = protectBlock, "system directory "+d
+			return v
+		}
+	}
+	if st, err := os.Stat(path); err == nil && st.IsDir() {
+		return pp.checkTree(path)
+	}
+	if pp.running[normalizePath(path)] {
+		v.Level, v.Reason = protectBlock, "executable of a running process"
+		return v
+	}
+	if pp.inUse(path) {
+		v.Level, v.Reason = protectWarn, "open in another process"
+		return v
+	}
+	if executableExtensions[strings.ToLower(filepath.Ext(path))] {
+		v.Level, v.Reason = protectWarn, "program file"
+		return v
+	}
+	if fileCategory(path) != categoryCaches {
+		for _, d := range pp.appDataDirs {
+			if isUnder(path, d) {
+				v.Level, v.Reason = protectWarn, "application data"
+				return v
+			}
+		}
+	}
+	return v
+}
+
+// cleanable reports whether path is inside, not equal to, one of the
+// cleanable system folders.
+func (pp *ProtectionPolicy) cleanable(path string) bool {
+	for _, d := range pp.cleanableDirs {
+		if isUnder(path, d) && normalizePath(path) != normalizePath(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTree checks the files inside a folder that is about to be deleted.
+// The folder gets the strictest verdict of any file in it.
+func (pp *ProtectionPolicy) checkTree(dir string) ProtectionVerdict {
+	v := ProtectionVerdict{Path: dir}
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		fv := pp.check(p)
+		if fv.Level > v.Level {
+			rel, _ := filepath.Rel(dir, p)
+			v.Level, v.Reason = fv.Level, fmt.Sprintf("contains %s (%s)", rel, fv.Reason)
+		}
+		if v.Level == protectBlock {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return v
+}
+
+// ---------------------------------------------------------------------
+//  2) Guarded Confirmation
+// ---------------------------------------------------------------------
+
+// confirmGuarded checks paths against the protection policy and shows a
+// confirmation listing what will be skipped and why. proceed is called with
+// the paths that are allowed once the user confirms.
+func (s *FileScanner) confirmGuarded(title, verb string, paths []string, proceed func(allowed []string)) {
+	s.confirmGuardedIn(s.mainWindow, title, verb, paths, proceed)
+}
+
+// confirmGuardedIn is confirmGuarded with the dialogs shown over parent.
+func (s *FileScanner) confirmGuardedIn(parent fyne.Window, title, verb string, paths []string, proceed func(allowed []string)) {
+	pp := s.protectionPolicy()
+
+	var allowed []string
+	var blocked, warned []ProtectionVerdict
+	for _, p := range paths {
+		v := pp.check(p)
+		switch v.Level {
+		case protectBlock:
+			blocked = append(blocked, v)
+		case protectWarn:
+			warned = append(warned, v)
+			allowed = append(allowed, p)
+		default:
+			allowed = append(allowed, p)
+		}
+	}
+
+	var details strings.Builder
+	if len(warned) > 0 {
+		details.WriteString(fmt.Sprintf("\n\nWarning — %d file(s) may still be needed:\n", len(warned)))
+		for _, v := range warned {
+			details.WriteString(fmt.Sprintf("  %s — %s\n", v.Path, v.Reason))
+		}
+	}
+	if len(blocked) > 0 {
+		details.WriteString(fmt.Sprintf("\n\nBlocked — %d file(s) will be skipped:\n", len(blocked)))
+		for _, v := range blocked {
+			details.WriteString(fmt.Sprintf("  %s — %s\n", v.Path, v.Reason))
+		}
+	}
+
+	if len(allowed) == 0 {
+		body := container.NewVScroll(widget.NewLabel("All selected files are protected." + details.String()))
+		body.SetMinSize(fyne.NewSize(600, 300))
+		dialog.ShowCustom("Nothing To "+verb, "OK", body, parent)
+		return
+	}
+	msg := fmt.Sprintf("%s %d file(s)?", verb, len(allowed)) + details.String()
+	if len(warned) == 0 && len(blocked) == 0 {
+		dialog.ShowConfirm(title, msg, func(c bool) {
+			if c {
+				proceed(allowed)
+			}
+		}, parent)
+		return
+	}
+
+	body := container.NewVScroll(widget.NewLabel(msg))
+	body.SetMinSize(fyne.NewSize(600, 300))
+	dlg := dialog.NewCustomConfirm(title, verb, "Cancel", body, func(c bool) {
+		if c {
+			proceed(allowed)
+		}
+	}, parent)
+	dlg.Show()
+}
+
+// confirmCaution asks once more before Space Cleaner files from targets
+// marked caution are removed, as they hold application or system files.
+// proceed is called right away when none of paths are in such a target.
+func (s *FileScanner) confirmCaution(paths []string, proceed func()) {
+	var dirs []string
+	s.mu.Lock()
+	for _, t := range s.scTargets {
+		if t.caution {
+			dirs = append(dirs, t.dir)
+		}
+	}
+	s.mu.Unlock()
+	var n int
+	for _, p := range paths {
+		if _, ok := underAny(dirs, p); ok {
+			n++
+		}
+	}
+	if n == 0 {
+		proceed()
+		return
+	}
+	dialog.ShowConfirm("Caution Targets", fmt.Sprintf(
+		"%d selected file(s) are in targets marked caution, which hold application or system files.\nRemoving them can break programs. Continue?", n), func(c bool) {
+		if c {
+			proceed()
+		}
+	}, s.mainWindow)
+}
+
+// showProtectedPathsDialog edits the user-defined protected paths, one per line.
+func (s *FileScanner) showProtectedPathsDialog() {
+	paths, err := loadProtectedPaths()
+	if err != nil {
+		dialog.ShowError(err, s.mainWindow)
+		return
+	}
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("One path per line, e.g. %USERPROFILE%\\Documents or ~/Photos")
+	entry.SetText(strings.Join(paths, "\n"))
+	wrap := container.NewGridWrap(fyne.NewSize(500, 200), entry)
+
+	dialog.ShowCustomConfirm("Protected Paths", "Save", "Cancel", wrap, func(c bool) {
+		if !c {
+			return
+		}
+		var res []string
+		for _, line := range strings.Split(entry.Text, "\n") {
+			if trim := strings.TrimSpace(line); trim != "" {
+				res = append(res, trim)
+			}
+		}
+		if err := saveProtectedPaths(res); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+		}
+	}, s.mainWindow)
+}
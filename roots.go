@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Folder roles in the Duplicate Finder
+const (
+	roleCandidate = "Candidate" // copies here may be selected for removal
+	roleReference = "Reference" // never touched; its copies elsewhere are selected
+)
+
+var rootRoles = []string{roleCandidate, roleReference}
+
+// ScanRoot is a folder the Duplicate Finder scans.
+type ScanRoot struct {
+	Path      string
+	Reference bool
+}
+
+func (r ScanRoot) role() string {
+	if r.Reference {
+		return roleReference
+	}
+	return roleCandidate
+}
+
+// ---------------------------------------------------------------------
+//  1) Roots and Roles
+// ---------------------------------------------------------------------
+
+// duplicateRoots returns the directory typed in the Duplicate Finder as a
+// candidate, followed by the further folders from the settings.
+func (s *FileScanner) duplicateRoots(dir string) []ScanRoot {
+	var roots []ScanRoot
+	seen := make(map[string]bool)
+	add := func(r ScanRoot) {
+		r.Path = strings.TrimSpace(r.Path)
+		if r.Path == "" || seen[normalizePath(r.Path)] {
+			return
+		}
+		seen[normalizePath(r.Path)] = true
+		roots = append(roots, r)
+	}
+	add(ScanRoot{Path: dir})
+	for _, r := range s.settings.DuplicateRoots {
+		add(r)
+	}
+	return roots
+}
+
+// outermostRoots drops roots that lie inside another root, so no folder
+// is walked twice.
+func outermostRoots(roots []ScanRoot) []string {
+	var res []string
+	for i, r := range roots {
+		nested := false
+		for j, o := range roots {
+			if i != j && isUnder(r.Path, o.Path) && normalizePath(r.Path) != normalizePath(o.Path) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			res = append(res, r.Path)
+		}
+	}
+	return res
+}
+
+// rootOf returns the innermost root holding path. Files inside archives
+// belong to the root of the archive, as their virtual paths start with it.
+func rootOf(roots []ScanRoot, path string) (ScanRoot, bool) {
+	var best ScanRoot
+	found := false
+	for _, r := range roots {
+		if isUnder(path, r.Path) && (!found || len(r.Path) > len(best.Path)) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+func hasReference(roots []ScanRoot) bool {
+	for _, r := range roots {
+		if r.Reference {
+			return true
+		}
+	}
+	return false
+}
+
+// scanRoots lists the files of every root, and the paths it skipped.
+func (s *FileScanner) scanRoots(roots []ScanRoot, filter FileFilter) ([]string, []SkippedPath, error) {
+	var files []string
+	var skipped []SkippedPath
+	for _, dir := range outermostRoots(roots) {
+		fs, sk, err := s.scanDirectory(dir, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, fs...)
+		skipped = append(skipped, sk...)
+	}
+	return files, skipped, nil
+}
+
+// applyRoles marks files in reference folders. Groups without a candidate
+// are dropped, as there is nothing to clean up in them, and candidates that
+// have a copy in a reference folder are selected.
+func applyRoles(roots []ScanRoot, items []*FileItem, groups map[string][]string) ([]*FileItem, map[string][]string) {
+	if !hasReference(roots) {
+		return items, groups
+	}
+	reference := make(map[string]bool)
+	keep := make(map[string]bool)
+	selected := make(map[string]bool)
+	res := make(map[string][]string)
+	for key, group := range groups {
+		var refs, cands int
+		for _, p := range group {
+			if r, _ := rootOf(roots, p); r.Reference {
+				reference[p] = true
+				refs++
+			} else {
+				cands++
+			}
+		}
+		if cands == 0 {
+			continue
+		}
+		res[key] = group
+		for _, p := range group {
+			keep[p] = true
+			if refs > 0 && !reference[p] {
+				selected[p] = true
+			}
+		}
+	}
+
+	var kept []*FileItem
+	for _, fi := range items {
+		if !keep[fi.filePath] {
+			continue
+		}
+		if reference[fi.filePath] {
+			fi.reference = true
+			if fi.note == "" {
+				fi.note = "reference"
+			} else {
+				fi.note += ", reference"
+			}
+		}
+		fi.selected = selected[fi.filePath]
+		kept = append(kept, fi)
+	}
+	return kept, res
+}
+
+// referenceDirs returns the reference folders of the last scan.
+func (s *FileScanner) referenceDirs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dirs []string
+	for _, r := range s.dfRoots {
+		if r.Reference {
+			dirs = append(dirs, r.Path)
+		}
+	}
+	return dirs
+}
+
+// protectionPolicy is newProtectionPolicy plus the Duplicate Finder's
+// reference folders.
+func (s *FileScanner) protectionPolicy() *ProtectionPolicy {
+	pp := newProtectionPolicy()
+	pp.referenceDirs = s.referenceDirs()
+	return pp
+}
+
+// ---------------------------------------------------------------------
+//  2) Folders Dialog
+// ---------------------------------------------------------------------
+
+// rootsSummary describes the further folders, e.g. "+2 folders (1 reference)".
+func rootsSummary(roots []ScanRoot) string {
+	if len(roots) == 0 {
+		return "No further folders"
+	}
+	var refs int
+	for _, r := range roots {
+		if r.Reference {
+			refs++
+		}
+	}
+	return fmt.Sprintf("+%d folder(s) (%d reference)", len(roots), refs)
+}
+
+// showRootsDialog edits the folders scanned besides the directory entry,
+// each with its role, and saves them in the settings.
+func (s *FileScanner) showRootsDialog(onSaved func()) {
+	roots := append([]ScanRoot(nil), s.settings.DuplicateRoots...)
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int { return len(roots) },
+		func() fyne.CanvasObject {
+			role := widget.NewSelect(rootRoles, nil)
+			remove := widget.NewButton("Remove", nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(role, remove), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(roots[id].Path)
+			buttons := row.Objects[1].(*fyne.Container)
+			role := buttons.Objects[0].(*widget.Select)
+			role.OnChanged = nil
+			role.SetSelected(roots[id].role())
+			role.OnChanged = func(v string) { roots[id].Reference = v == roleReference }
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				roots = append(roots[:id], roots[id+1:]...)
+				list.Refresh()
+			}
+		},
+	)
+
+	addBtn := widget.NewButton("Add Folder", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			roots = append(roots, ScanRoot{Path: filepath.FromSlash(uri.Path())})
+			list.Refresh()
+		}, s.mainWindow)
+	})
+	help := widget.NewLabel("Reference folders are never changed. Copies of their files in candidate\nfolders are selected for removal. The directory above is always a candidate.")
+	content := container.NewBorder(container.NewVBox(help, addBtn), nil, nil, nil, list)
+
+	dlg := dialog.NewCustomConfirm("Folders To Compare", "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		st := s.settings
+		st.DuplicateRoots = roots
+		if err := saveSettings(st); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return
+		}
+		s.settings = st
+		onSaved()
+	}, s.mainWindow)
+	dlg.Resize(fyne.NewSize(700, 400))
+	dlg.Show()
+}
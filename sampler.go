@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ---------------------------------------------------------------------
+//  Background System Sampler
+// ---------------------------------------------------------------------
+
+const sampleInterval = 5 * time.Second
+
+type DiskSample struct {
+	Mount       string
+	Total       uint64
+	Free        uint64
+	FreePercent float64
+}
+
+// SystemSample is a single reading of the machine taken by the sampler.
+type SystemSample struct {
+	Time       time.Time
+	CPUPercent float64
+	MemPercent float64
+	Disks      []DiskSample
+	Processes  map[string]bool // lower-cased names of running processes; nil if not listed
+}
+
+// SystemSampler periodically reads CPU, memory, disk and process state and
+// hands every sample to its subscribers.
+type SystemSampler struct {
+	interval time.Duration
+
+	mu          sync.Mutex
+	subscribers []func(SystemSample)
+	last        SystemSample
+	stop        chan struct{}
+
+	// wantProcesses, if set, is asked before every sample whether the
+	// process list is needed; listing processes is comparatively slow.
+	wantProcesses func() bool
+}
+
+func newSystemSampler(interval time.Duration) *SystemSampler {
+	return &SystemSampler{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// subscribe registers fn to be called (on the sampler goroutine) with every new sample.
+func (ss *SystemSampler) subscribe(fn func(SystemSample)) {
+	ss.mu.Lock()
+	ss.subscribers = append(ss.subscribers, fn)
+	ss.mu.Unlock()
+}
+
+// lastSample returns the most recent reading.
+func (ss *SystemSampler) lastSample() SystemSample {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.last
+}
+
+func (ss *SystemSampler) start() {
+	go func() {
+		ticker := time.NewTicker(ss.interval)
+		defer ticker.Stop()
+		ss.sampleOnce()
+		for {
+			select {
+			case <-ss.stop:
+				return
+			case <-ticker.C:
+				ss.sampleOnce()
+			}
+		}
+	}()
+}
+
+func (ss *SystemSampler) shutdown() {
+	close(ss.stop)
+}
+
+func (ss *SystemSampler) sampleOnce() {
+	sample := takeSystemSample(ss.wantProcesses != nil && ss.wantProcesses())
+
+	ss.mu.Lock()
+	ss.last = sample
+	subs := append([]func(SystemSample){}, ss.subscribers...)
+	ss.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(sample)
+	}
+}
+
+func takeSystemSample(withProcesses bool) SystemSample {
+	sample := SystemSample{Time: time.Now()}
+
+	if pcts, err := cpu.Percent(0, false); err == nil && len(pcts) > 0 {
+		sample.CPUPercent = pcts[0]
+	}
+	if vm, err := mem.VirtualMemory(); err == nil {
+		sample.MemPercent = vm.UsedPercent
+	}
+
+	parts, _ := disk.Partitions(false)
+	for _, p := range parts {
+		u, err := disk.Usage(p.Mountpoint)
+		if err != nil || u.Total == 0 {
+			continue
+		}
+		sample.Disks = append(sample.Disks, DiskSample{
+			Mount:       p.Mountpoint,
+			Total:       u.Total,
+			Free:        u.Free,
+			FreePercent: float64(u.Free) / float64(u.Total) * 100,
+		})
+	}
+
+	if !withProcesses {
+		return sample
+	}
+	sample.Processes = map[string]bool{}
+	procs, _ := process.Processes()
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil || name == "" {
+			continue
+		}
+		sample.Processes[strings.ToLower(name)] = true
+	}
+	return sample
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// ---------------------------------------------------------------------
+//  Hardware Sensors & Battery
+// ---------------------------------------------------------------------
+
+var (
+	errSensorNotAvailable = errors.New("not available on this platform")
+	errNoFans             = errors.New("no fan sensors found")
+	errNoBattery          = errors.New("no battery detected")
+)
+
+type TemperatureReading struct {
+	Sensor   string
+	Celsius  float64
+	High     float64 // 0 when the sensor reports no limit
+	Critical float64
+}
+
+type FanReading struct {
+	Sensor string
+	RPM    float64
+}
+
+type BatteryReading struct {
+	Name          string
+	ChargePercent float64
+	State         string        // "Charging", "Discharging", "Full", ...
+	RateWatts     float64       // 0 when unknown
+	HealthPercent float64       // full capacity vs design capacity, -1 when unknown
+	TimeRemaining time.Duration // 0 when unknown
+}
+
+// SensorProvider reads temperatures, fans and batteries. Methods return
+// errSensorNotAvailable when the platform has no way of reporting them.
+type SensorProvider interface {
+	Temperatures() ([]TemperatureReading, error)
+	Fans() ([]FanReading, error)
+	Batteries() ([]BatteryReading, error)
+}
+
+// newSensorProvider returns the real provider, or the fake one when
+// WINTOOL_FAKE_SENSORS is set (for machines without sensors).
+func newSensorProvider() SensorProvider {
+	if os.Getenv("WINTOOL_FAKE_SENSORS") != "" {
+		return fakeSensorProvider{}
+	}
+	return systemSensorProvider{}
+}
+
+// systemSensorProvider uses gopsutil for temperatures and the platform
+// specific readFans/readBatteries for the rest.
+type systemSensorProvider struct{}
+
+func (systemSensorProvider) Temperatures() ([]TemperatureReading, error) {
+	temps, err := host.SensorsTemperatures()
+	// gopsutil returns partial results together with warnings
+	if len(temps) == 0 {
+		if err == nil {
+			err = errSensorNotAvailable
+		}
+		return nil, err
+	}
+	var res []TemperatureReading
+	for _, t := range temps {
+		res = append(res, TemperatureReading{
+			Sensor:   t.SensorKey,
+			Celsius:  t.Temperature,
+			High:     t.High,
+			Critical: t.Critical,
+		})
+	}
+	return res, nil
+}
+
+func (systemSensorProvider) Fans() ([]FanReading, error) {
+	return readFans()
+}
+
+func (systemSensorProvider) Batteries() ([]BatteryReading, error) {
+	return readBatteries()
+}
+
+// fakeSensorProvider returns fixed readings.
+type fakeSensorProvider struct{}
+
+func (fakeSensorProvider) Temperatures() ([]TemperatureReading, error) {
+	return []TemperatureReading{
+		{Sensor: "cpu_package", Celsius: 52.0, High: 90, Critical: 100},
+		{Sensor: "nvme_composite", Celsius: 38.5, High: 80, Critical: 85},
+	}, nil
+}
+
+func (fakeSensorProvider) Fans() ([]FanReading, error) {
+	return []FanReading{{Sensor: "cpu_fan", RPM: 1450}}, nil
+}
+
+func (fakeSensorProvider) Batteries() ([]BatteryReading, error) {
+	return []BatteryReading{{
+		Name:          "BAT0",
+		ChargePercent: 76,
+		State:         "Discharging",
+		RateWatts:     9.8,
+		HealthPercent: 88.2,
+		TimeRemaining: 3*time.Hour + 12*time.Minute,
+	}}, nil
+}
+
+// sensorDetails formats the readings in the same style as the rest of System Info.
+func sensorDetails(p SensorProvider) string {
+	var sb strings.Builder
+
+	sb.WriteString("=== Temperatures ===\n")
+	if temps, err := p.Temperatures(); err != nil {
+		sb.WriteString(fmt.Sprintf("Not available (%v)\n", err))
+	} else {
+		for _, t := range temps {
+			sb.WriteString(fmt.Sprintf("%s: %.1f °C", t.Sensor, t.Celsius))
+			if t.Critical > 0 {
+				sb.WriteString(fmt.Sprintf(" (critical %.0f °C)", t.Critical))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n=== Fans ===\n")
+	if fans, err := p.Fans(); err != nil {
+		sb.WriteString(fmt.Sprintf("Not available (%v)\n", err))
+	} else {
+		for _, f := range fans {
+			sb.WriteString(fmt.Sprintf("%s: %.0f RPM\n", f.Sensor, f.RPM))
+		}
+	}
+
+	sb.WriteString("\n=== Battery ===\n")
+	if bats, err := p.Batteries(); err != nil {
+		sb.WriteString(fmt.Sprintf("Not available (%v)\n", err))
+	} else {
+		for _, b := range bats {
+			sb.WriteString(fmt.Sprintf("%s:\n  Charge: %.0f%%\n  State: %s\n", b.Name, b.ChargePercent, b.State))
+			if b.RateWatts > 0 {
+				sb.WriteString(fmt.Sprintf("  Rate: %.1f W\n", b.RateWatts))
+			} else {
+				sb.WriteString("  Rate: not available\n")
+			}
+			if b.HealthPercent >= 0 {
+				sb.WriteString(fmt.Sprintf("  Health: %.1f%%\n", b.HealthPercent))
+			} else {
+				sb.WriteString("  Health: not available\n")
+			}
+			if b.TimeRemaining > 0 {
+				sb.WriteString(fmt.Sprintf("  Time Remaining: %s\n", b.TimeRemaining.Round(time.Minute)))
+			} else {
+				sb.WriteString("  Time Remaining: not available\n")
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readSysfs returns the trimmed contents of a sysfs attribute, or "" if it is missing.
+func readSysfs(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysfsFloat parses a numeric sysfs attribute; ok is false if it is missing.
+func readSysfsFloat(path string) (float64, bool) {
+	v, err := strconv.ParseFloat(readSysfs(path), 64)
+	return v, err == nil
+}
+
+func readFans() ([]FanReading, error) {
+	inputs, _ := filepath.Glob("/sys/class/hwmon/hwmon*/fan*_input")
+	var fans []FanReading
+	for _, in := range inputs {
+		rpm, ok := readSysfsFloat(in)
+		if !ok {
+			continue
+		}
+		dir := filepath.Dir(in)
+		name := readSysfs(filepath.Join(dir, "name"))
+		fan := strings.TrimSuffix(filepath.Base(in), "_input")
+		if label := readSysfs(filepath.Join(dir, fan+"_label")); label != "" {
+			fan = label
+		}
+		fans = append(fans, FanReading{Sensor: name + " " + fan, RPM: rpm})
+	}
+	if len(fans) == 0 {
+		return nil, errNoFans
+	}
+	return fans, nil
+}
+
+// readBatteries reads /sys/class/power_supply. Drivers report either energy
+// (µWh, µW) or charge (µAh, µA) attributes, so both are tried.
+func readBatteries() ([]BatteryReading, error) {
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	var bats []BatteryReading
+	for _, dir := range supplies {
+		if readSysfs(filepath.Join(dir, "type")) != "Battery" {
+			continue
+		}
+		b := BatteryReading{
+			Name:          filepath.Base(dir),
+			State:         readSysfs(filepath.Join(dir, "status")),
+			HealthPercent: -1,
+		}
+		if c, ok := readSysfsFloat(filepath.Join(dir, "capacity")); ok {
+			b.ChargePercent = c
+		}
+
+		now, okNow := readSysfsFloat(filepath.Join(dir, "energy_now"))
+		full, okFull := readSysfsFloat(filepath.Join(dir, "energy_full"))
+		design, okDesign := readSysfsFloat(filepath.Join(dir, "energy_full_design"))
+		rate, okRate := readSysfsFloat(filepath.Join(dir, "power_now"))
+		voltage, okVolt := readSysfsFloat(filepath.Join(dir, "voltage_now"))
+		if !okNow {
+			now, okNow = readSysfsFloat(filepath.Join(dir, "charge_now"))
+			full, okFull = readSysfsFloat(filepath.Join(dir, "charge_full"))
+			design, okDesign = readSysfsFloat(filepath.Join(dir, "charge_full_design"))
+			rate, okRate = readSysfsFloat(filepath.Join(dir, "current_now"))
+			if okRate && okVolt {
+				// µA * µV -> µW
+				b.RateWatts = rate * voltage / 1e12
+			}
+		} else if okRate {
+			b.RateWatts = rate / 1e6
+		}
+
+		if okFull && okDesign && design > 0 {
+			b.HealthPercent = full / design * 100
+		}
+		if okNow && okRate && rate > 0 {
+			switch b.State {
+			case "Discharging":
+				b.TimeRemaining = time.Duration(now / rate * float64(time.Hour))
+			case "Charging":
+				if okFull && full > now {
+					b.TimeRemaining = time.Duration((full - now) / rate * float64(time.Hour))
+				}
+			}
+		}
+		bats = append(bats, b)
+	}
+	if len(bats) == 0 {
+		return nil, errNoBattery
+	}
+	return bats, nil
+}
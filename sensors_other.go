@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package main
+
+func readFans() ([]FanReading, error) {
+	return nil, errSensorNotAvailable
+}
+
+func readBatteries() ([]BatteryReading, error) {
+	return nil, errSensorNotAvailable
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubSensorProvider returns whatever it is given, so tests can mix
+// readings and errors.
+type stubSensorProvider struct {
+	temps   []TemperatureReading
+	tempErr error
+	fans    []FanReading
+	fanErr  error
+	bats    []BatteryReading
+	batErr  error
+}
+
+func (p stubSensorProvider) Temperatures() ([]TemperatureReading, error) { return p.temps, p.tempErr }
+func (p stubSensorProvider) Fans() ([]FanReading, error)                 { return p.fans, p.fanErr }
+func (p stubSensorProvider) Batteries() ([]BatteryReading, error)        { return p.bats, p.batErr }
+
+func TestNewSensorProvider(t *testing.T) {
+	t.Setenv("WINTOOL_FAKE_SENSORS", "1")
+	if _, ok := newSensorProvider().(fakeSensorProvider); !ok {
+		t.Fatal("WINTOOL_FAKE_SENSORS set, want the fake provider")
+	}
+	t.Setenv("WINTOOL_FAKE_SENSORS", "")
+	if _, ok := newSensorProvider().(systemSensorProvider); !ok {
+		t.Fatal("WINTOOL_FAKE_SENSORS empty, want the system provider")
+	}
+}
+
+func TestSensorDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       SensorProvider
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "fake provider",
+			p:    fakeSensorProvider{},
+			want: []string{
+				"=== Temperatures ===\ncpu_package: 52.0 °C (critical 100 °C)\nnvme_composite: 38.5 °C (critical 85 °C)\n",
+				"=== Fans ===\ncpu_fan: 1450 RPM\n",
+				"BAT0:\n  Charge: 76%\n  State: Discharging\n",
+				"  Rate: 9.8 W\n",
+				"  Health: 88.2%\n",
+				"  Time Remaining: 3h12m0s\n",
+			},
+			notWant: []string{"Not available"},
+		},
+		{
+			name: "nothing on this platform",
+			p: stubSensorProvider{
+				tempErr: errSensorNotAvailable,
+				fanErr:  errSensorNotAvailable,
+				batErr:  errNoBattery,
+			},
+			want: []string{
+				"=== Temperatures ===\nNot available (not available on this platform)\n",
+				"=== Fans ===\nNot available (not available on this platform)\n",
+				"=== Battery ===\nNot available (no battery detected)\n",
+			},
+		},
+		{
+			name: "no fans",
+			p:    stubSensorProvider{fanErr: errNoFans, batErr: errNoBattery},
+			want: []string{"=== Fans ===\nNot available (no fan sensors found)\n"},
+		},
+		{
+			name: "other error",
+			p:    stubSensorProvider{tempErr: errors.New("access denied"), batErr: errNoBattery},
+			want: []string{"=== Temperatures ===\nNot available (access denied)\n"},
+		},
+		{
+			name: "battery without rate, health or time",
+			p: stubSensorProvider{bats: []BatteryReading{{
+				Name:          "BAT1",
+				ChargePercent: 100,
+				State:         "Full",
+				HealthPercent: -1,
+			}}},
+			want: []string{
+				"BAT1:\n  Charge: 100%\n  State: Full\n",
+				"  Rate: not available\n",
+				"  Health: not available\n",
+				"  Time Remaining: not available\n",
+			},
+		},
+		{
+			name: "charging battery",
+			p: stubSensorProvider{bats: []BatteryReading{{
+				Name:          "BAT0",
+				ChargePercent: 40,
+				State:         "Charging",
+				RateWatts:     20,
+				HealthPercent: 100,
+				TimeRemaining: 95*time.Minute + 20*time.Second,
+			}}},
+			want: []string{"  Rate: 20.0 W\n", "  Health: 100.0%\n", "  Time Remaining: 1h35m0s\n"},
+		},
+		{
+			name:    "temperature without limit",
+			p:       stubSensorProvider{temps: []TemperatureReading{{Sensor: "acpitz", Celsius: 27.8}}, batErr: errNoBattery},
+			want:    []string{"acpitz: 27.8 °C\n"},
+			notWant: []string{"critical"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sensorDetails(tt.p)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("missing %q in:\n%s", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("unexpected %q in:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
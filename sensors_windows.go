@@ -0,0 +1,60 @@
+package main
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var procGetSystemPowerStatus = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus mirrors the Win32 SYSTEM_POWER_STATUS structure.
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// readFans: Windows exposes fan speeds only through vendor specific WMI providers.
+func readFans() ([]FanReading, error) {
+	return nil, errSensorNotAvailable
+}
+
+// readBatteries uses GetSystemPowerStatus, which reports charge and time
+// remaining but not the discharge rate or wear level.
+func readBatteries() ([]BatteryReading, error) {
+	var st systemPowerStatus
+	r, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&st)))
+	if r == 0 {
+		return nil, err
+	}
+	// 128 = no system battery, 255 = unknown status
+	if st.BatteryFlag&128 != 0 || st.BatteryFlag == 255 {
+		return nil, errNoBattery
+	}
+
+	b := BatteryReading{
+		Name:          "System Battery",
+		HealthPercent: -1,
+	}
+	if st.BatteryLifePercent != 255 {
+		b.ChargePercent = float64(st.BatteryLifePercent)
+	}
+	switch {
+	case st.BatteryFlag&8 != 0:
+		b.State = "Charging"
+	case st.ACLineStatus == 1 && b.ChargePercent >= 100:
+		b.State = "Full"
+	case st.ACLineStatus == 1:
+		b.State = "Not charging"
+	default:
+		b.State = "Discharging"
+	}
+	if st.BatteryLifeTime != 0xFFFFFFFF {
+		b.TimeRemaining = time.Duration(st.BatteryLifeTime) * time.Second
+	}
+	return []BatteryReading{b}, nil
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const settingsFilePath = "settings.json" // User preferences edited on the Settings screen
+
+// ---------------------------------------------------------------------
+//  1) Settings
+// ---------------------------------------------------------------------
+
+var duplicateSorts = []string{"Path", "Size"}
+
+// Settings holds every user preference. Fields missing from settings.json
+// keep their defaults.
+type Settings struct {
+	DuplicateSort  string  // "Path" or "Size"
+	Theme          string  // one of themeNames
+	AccentColor    string  // "#RRGGBB"
+	Padding        float32 // theme padding in pixels
+	TextSize       float32
+	SplitOffset    float64    // share of the window used by the left menu
+	VaultPath      string     // encrypted password file
+	DefaultProfile string     // cleanup profile selected at startup; empty = one for this platform
+	DuplicateDir   string     // directory pre-filled in the Duplicate Finder
+	DuplicateRoots []ScanRoot // further Duplicate Finder folders and their roles
+	HashAlgorithm  string     // content hash for finding duplicates, one of hashAlgorithmNames
+	VerifyHashes   bool       // re-check groups found with a fast hash using SHA-256
+	FollowLinks    bool       // scans enter symbolic links and junctions
+	SameFilesystem bool       // scans stay on the file system of the folder they start in
+	MinSizeMB      float64    // Space Cleaner minimum size pre-filled at startup
+}
+
+func defaultSettings() Settings {
+	return Settings{
+		DuplicateSort: "Path",
+		Theme:         themeDark,
+		AccentColor:   defaultAccentColor,
+		Padding:       8,
+		TextSize:      14,
+		SplitOffset:   0.2,
+		VaultPath:     passwordFilePath,
+		MinSizeMB:     defaultMinSizeMB,
+		HashAlgorithm: defaultHashAlgorithm,
+		VerifyHashes:  true,
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// settingCheck checks one field without looking at the disk; reset puts
+// the field back to its default.
+type settingCheck struct {
+	check func(st Settings) error
+	reset func(st, def *Settings)
+}
+
+var settingChecks = []settingCheck{
+	{func(st Settings) error {
+		if !contains(duplicateSorts, st.DuplicateSort) {
+			return fmt.Errorf("unknown sort %q", st.DuplicateSort)
+		}
+		return nil
+	}, func(st, def *Settings) { st.DuplicateSort = def.DuplicateSort }},
+	{func(st Settings) error {
+		if !contains(themeNames, st.Theme) {
+			return fmt.Errorf("unknown theme %q", st.Theme)
+		}
+		return nil
+	}, func(st, def *Settings) { st.Theme = def.Theme }},
+	{func(st Settings) error {
+		if _, err := parseHexColor(st.AccentColor); err != nil {
+			return fmt.Errorf("accent %v", err)
+		}
+		return nil
+	}, func(st, def *Settings) { st.AccentColor = def.AccentColor }},
+	{func(st Settings) error {
+		if st.Padding < 0 || st.Padding > 20 {
+			return fmt.Errorf("padding must be between 0 and 20")
+		}
+		return nil
+	}, func(st, def *Settings) { st.Padding = def.Padding }},
+	{func(st Settings) error {
+		if st.TextSize < 8 || st.TextSize > 32 {
+			return fmt.Errorf("text size must be between 8 and 32")
+		}
+		return nil
+	}, func(st, def *Settings) { st.TextSize = def.TextSize }},
+	{func(st Settings) error {
+		if st.SplitOffset < 0.1 || st.SplitOffset > 0.5 {
+			return fmt.Errorf("menu width must be between 0.1 and 0.5")
+		}
+		return nil
+	}, func(st, def *Settings) { st.SplitOffset = def.SplitOffset }},
+	{func(st Settings) error {
+		if strings.TrimSpace(st.VaultPath) == "" {
+			return fmt.Errorf("vault path cannot be empty")
+		}
+		return nil
+	}, func(st, def *Settings) { st.VaultPath = def.VaultPath }},
+	{func(st Settings) error {
+		_, err := lookupHashAlgorithm(st.HashAlgorithm)
+		return err
+	}, func(st, def *Settings) { st.HashAlgorithm = def.HashAlgorithm }},
+	{func(st Settings) error {
+		if st.MinSizeMB < 0 {
+			return fmt.Errorf("minimum size cannot be negative")
+		}
+		return nil
+	}, func(st, def *Settings) { st.MinSizeMB = def.MinSizeMB }},
+}
+
+// validate checks settings entered by the user, including that the folders
+// and profile they name exist.
+func (st Settings) validate() error {
+	for _, c := range settingChecks {
+		if err := c.check(st); err != nil {
+			return err
+		}
+	}
+	if info, err := os.Stat(filepath.Dir(st.VaultPath)); err != nil || !info.IsDir() {
+		return fmt.Errorf("vault directory %s does not exist", filepath.Dir(st.VaultPath))
+	}
+	if st.DefaultProfile != "" {
+		profiles, _ := loadCleanupProfiles()
+		found := false
+		for _, p := range profiles {
+			found = found || p.Name == st.DefaultProfile
+		}
+		if !found {
+			return fmt.Errorf("unknown cleanup profile %q", st.DefaultProfile)
+		}
+	}
+	if st.DuplicateDir != "" {
+		if info, err := os.Stat(st.DuplicateDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("directory %s does not exist", st.DuplicateDir)
+		}
+	}
+	return nil
+}
+
+// parseSettings decodes saved settings. Only the format of each field is
+// checked; a folder that is missing today may be back tomorrow. Fields that
+// are invalid or of the wrong type keep their defaults and are reported in
+// the error, while the rest are still used.
+func parseSettings(data []byte) (Settings, error) {
+	st := defaultSettings()
+	var errs []error
+	if err := json.Unmarshal(data, &st); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return defaultSettings(), err
+		}
+		errs = append(errs, err) // the other fields were still decoded
+	}
+	if st.Theme == "Fyne default" {
+		st.Theme = themeSystem // name used before the theme variants were added
+	}
+	def := defaultSettings()
+	for _, c := range settingChecks {
+		if err := c.check(st); err != nil {
+			errs = append(errs, err)
+			c.reset(&st, &def)
+		}
+	}
+	return st, errors.Join(errs...)
+}
+
+// loadSettings returns the saved settings, or the defaults when the file is
+// missing or invalid.
+func loadSettings() (Settings, error) {
+	data, err := os.ReadFile(settingsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultSettings(), nil
+		}
+		return defaultSettings(), err
+	}
+	st, err := parseSettings(data)
+	if err != nil {
+		return st, fmt.Errorf("decoding %s: %w", settingsFilePath, err)
+	}
+	return st, nil
+}
+
+func saveSettings(st Settings) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(settingsFilePath, data, 0644)
+}
+
+// applySettings makes new settings take effect in the running app.
+func (s *FileScanner) applySettings(st Settings) {
+	old := s.settings
+	s.settings = st
+
+	fyne.CurrentApp().Settings().SetTheme(newAppTheme(st))
+	if s.split != nil {
+		s.split.Offset = st.SplitOffset
+		s.split.Refresh()
+	}
+
+	if s.dfSortSelect != nil && old.DuplicateSort != st.DuplicateSort {
+		s.dfSortSelect.SetSelected(st.DuplicateSort) // also refreshes the list
+	} else {
+		s.refreshDuplicates()
+	}
+	s.refreshLargeFiles()
+	if s.dfRootsLabel != nil {
+		s.dfRootsLabel.SetText(rootsSummary(st.DuplicateRoots))
+	}
+
+	if old.VaultPath != st.VaultPath {
+		s.passwordManagerRoot = s.setupPasswordManagerUI()
+	}
+}
+
+// ---------------------------------------------------------------------
+//  2) Settings UI
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) setupSettingsUI() fyne.CanvasObject {
+	sortSelect := widget.NewSelect(duplicateSorts, nil)
+	themeSelect := widget.NewSelect(themeNames, nil)
+	accentEntry := widget.NewEntry()
+	accentSwatch := canvas.NewRectangle(color.Transparent)
+	accentSwatch.SetMinSize(fyne.NewSize(24, 24))
+	accentEntry.OnChanged = func(text string) {
+		if c, err := parseHexColor(text); err == nil {
+			accentSwatch.FillColor = c
+			accentSwatch.Refresh()
+		}
+	}
+	pickAccent := widget.NewButton("Pick", func() {
+		picker := dialog.NewColorPicker("Accent Colour", "Choose the accent colour", func(c color.Color) {
+			accentEntry.SetText(formatHexColor(c))
+		}, s.mainWindow)
+		picker.Advanced = true
+		if c, err := parseHexColor(accentEntry.Text); err == nil {
+			picker.SetColor(c)
+		}
+		picker.Show()
+	})
+	paddingEntry := widget.NewEntry()
+	textSizeEntry := widget.NewEntry()
+	offsetEntry := widget.NewEntry()
+	vaultEntry := widget.NewEntry()
+	profiles, _ := loadCleanupProfiles()
+	profileOptions := []string{"(platform default)"}
+	for _, p := range profiles {
+		profileOptions = append(profileOptions, p.Name)
+	}
+	profileSelect := widget.NewSelect(profileOptions, nil)
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder("none")
+	minSizeEntry := widget.NewEntry()
+	hashSelect := widget.NewSelect(hashAlgorithmNames(), nil)
+	verifyCheck := widget.NewCheck("Verify fast-hash matches with SHA-256", nil)
+	followCheck := widget.NewCheck("Follow symbolic links and junctions", nil)
+	sameFSCheck := widget.NewCheck("Stay on one file system", nil)
+
+	show := func(st Settings) {
+		sortSelect.SetSelected(st.DuplicateSort)
+		themeSelect.SetSelected(st.Theme)
+		accentEntry.SetText(st.AccentColor)
+		paddingEntry.SetText(strconv.FormatFloat(float64(st.Padding), 'f', -1, 32))
+		textSizeEntry.SetText(strconv.FormatFloat(float64(st.TextSize), 'f', -1, 32))
+		offsetEntry.SetText(strconv.FormatFloat(st.SplitOffset, 'f', -1, 64))
+		vaultEntry.SetText(st.VaultPath)
+		if st.DefaultProfile == "" {
+			profileSelect.SetSelected(profileOptions[0])
+		} else {
+			profileSelect.SetSelected(st.DefaultProfile)
+		}
+		dirEntry.SetText(st.DuplicateDir)
+		hashSelect.SetSelected(st.HashAlgorithm)
+		verifyCheck.SetChecked(st.VerifyHashes)
+		followCheck.SetChecked(st.FollowLinks)
+		sameFSCheck.SetChecked(st.SameFilesystem)
+		minSizeEntry.SetText(strconv.FormatFloat(st.MinSizeMB, 'f', -1, 64))
+	}
+
+	// read collects the form into Settings, checking every field
+	read := func() (Settings, error) {
+		st := s.settings
+		var err error
+		st.DuplicateSort = sortSelect.Selected
+		st.Theme = themeSelect.Selected
+		st.AccentColor = strings.ToUpper(strings.TrimSpace(accentEntry.Text))
+		padding, err := strconv.ParseFloat(strings.TrimSpace(paddingEntry.Text), 32)
+		if err != nil {
+			return st, fmt.Errorf("padding must be a number")
+		}
+		st.Padding = float32(padding)
+		textSize, err := strconv.ParseFloat(strings.TrimSpace(textSizeEntry.Text), 32)
+		if err != nil {
+			return st, fmt.Errorf("text size must be a number")
+		}
+		st.TextSize = float32(textSize)
+		if st.SplitOffset, err = strconv.ParseFloat(strings.TrimSpace(offsetEntry.Text), 64); err != nil {
+			return st, fmt.Errorf("menu width must be a number")
+		}
+		st.VaultPath = strings.TrimSpace(vaultEntry.Text)
+		st.DefaultProfile = profileSelect.Selected
+		if st.DefaultProfile == profileOptions[0] {
+			st.DefaultProfile = ""
+		}
+		st.DuplicateDir = strings.TrimSpace(dirEntry.Text)
+		st.HashAlgorithm = hashSelect.Selected
+		st.VerifyHashes = verifyCheck.Checked
+		st.FollowLinks = followCheck.Checked
+		st.SameFilesystem = sameFSCheck.Checked
+		if st.MinSizeMB, err = strconv.ParseFloat(strings.TrimSpace(minSizeEntry.Text), 64); err != nil {
+			return st, fmt.Errorf("minimum size must be a number of MB")
+		}
+		return st, st.validate()
+	}
+
+	commit := func(st Settings) {
+		if err := saveSettings(st); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+			return
+		}
+		s.applySettings(st)
+		show(st)
+	}
+
+	saveBtn := s.commandButton(toolSettings, "Save", nil, func() {
+		st, err := read()
+		if err != nil {
+			dialog.ShowInformation("Invalid Settings", err.Error(), s.mainWindow)
+			return
+		}
+		commit(st)
+		dialog.ShowInformation("Saved", "Settings saved.", s.mainWindow)
+	})
+	resetBtn := s.commandButton(toolSettings, "Restore Defaults", nil, func() {
+		dialog.ShowConfirm("Restore Defaults", "Replace all settings with the defaults?", func(c bool) {
+			if c {
+				commit(defaultSettings())
+			}
+		}, s.mainWindow)
+	})
+	importBtn := s.commandButton(toolSettings, "Import", nil, func() {
+		dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			defer r.Close()
+			data, err := io.ReadAll(r)
+			if err != nil {
+				dialog.ShowError(err, s.mainWindow)
+				return
+			}
+			st, err := parseSettings(data)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid settings file: %v", err), s.mainWindow)
+				return
+			}
+			commit(st)
+			dialog.ShowInformation("Imported", "Settings imported.", s.mainWindow)
+		}, s.mainWindow)
+	})
+	exportBtn := s.commandButton(toolSettings, "Export", nil, func() {
+		dialog.ShowFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			data, err := json.MarshalIndent(s.settings, "", "  ")
+			if err != nil {
+				dialog.ShowError(err, s.mainWindow)
+				return
+			}
+			w.Write(data)
+			dialog.ShowInformation("Exported", "Settings exported.", s.mainWindow)
+		}, s.mainWindow)
+	})
+
+	// The vault is only chosen here, never opened: picking the folder keeps
+	// the file name, so an existing vault is not touched.
+	browseVault := widget.NewButton("Browse", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			name := filepath.Base(strings.TrimSpace(vaultEntry.Text))
+			if name == "." || name == string(filepath.Separator) {
+				name = filepath.Base(passwordFilePath)
+			}
+			vaultEntry.SetText(filepath.Join(uri.Path(), name))
+		}, s.mainWindow)
+	})
+	browseDir := widget.NewButton("Browse", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dirEntry.SetText(uri.Path())
+		}, s.mainWindow)
+	})
+
+	show(s.settings)
+	form := widget.NewForm(
+		widget.NewFormItem("Duplicate Finder sort", sortSelect),
+		widget.NewFormItem("Duplicate Finder directory", container.NewBorder(nil, nil, nil, browseDir, dirEntry)),
+		widget.NewFormItem("Duplicate Finder hash", container.NewHBox(hashSelect, verifyCheck)),
+		widget.NewFormItem("Scanning", container.NewHBox(followCheck, sameFSCheck)),
+		widget.NewFormItem("Space Cleaner profile", profileSelect),
+		widget.NewFormItem("Space Cleaner minimum size (MB)", minSizeEntry),
+		widget.NewFormItem("Theme", themeSelect),
+		widget.NewFormItem("Accent colour", container.NewBorder(nil, nil, accentSwatch, pickAccent, accentEntry)),
+		widget.NewFormItem("Padding", paddingEntry),
+		widget.NewFormItem("Text size", textSizeEntry),
+		widget.NewFormItem("Menu width (0.1–0.5)", offsetEntry),
+		widget.NewFormItem("Password vault", container.NewBorder(nil, nil, nil, browseVault, vaultEntry)),
+	)
+
+	return container.NewBorder(
+		widget.NewLabel("Settings are stored in "+settingsFilePath+". Directory, profile and minimum size are used when the app starts."),
+		container.NewHBox(saveBtn, resetBtn, layout.NewSpacer(), importBtn, exportBtn),
+		nil, nil,
+		container.NewVScroll(form),
+	)
+}
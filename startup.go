@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const startupChangesFilePath = "startup_changes.json" // Record of startup items disabled by this tool
+
+// ---------------------------------------------------------------------
+//  1) Startup Sources
+// ---------------------------------------------------------------------
+
+// StartupItem is one program or service that runs at boot or login.
+type StartupItem struct {
+	Source   string // name of the StartupSource that listed it
+	ID       string // identifier the source uses to find the item again
+	Name     string
+	Command  string
+	Location string // file, registry key or unit the item lives in
+	Enabled  bool
+}
+
+// StartupSource lists and toggles one kind of autostart entry (XDG autostart
+// files, systemd units, registry Run keys, Windows services, ...).
+//
+// Disable returns an opaque undo token that Restore accepts to put the item
+// back exactly as it was.
+type StartupSource interface {
+	Name() string
+	List() ([]StartupItem, error)
+	Disable(item StartupItem) (undo string, err error)
+	Restore(item StartupItem, undo string) error
+}
+
+// StartupChange is the reversible record kept for every item disabled here.
+type StartupChange struct {
+	Timestamp string
+	Item      StartupItem
+	Undo      string
+	Restored  bool
+}
+
+func loadStartupChanges() ([]StartupChange, error) {
+	data, err := os.ReadFile(startupChangesFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var changes []StartupChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", startupChangesFilePath, err)
+	}
+	return changes, nil
+}
+
+func saveStartupChanges(changes []StartupChange) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(startupChangesFilePath, data, 0644)
+}
+
+func findStartupSource(sources []StartupSource, name string) StartupSource {
+	for _, src := range sources {
+		if src.Name() == name {
+			return src
+		}
+	}
+	return nil
+}
+
+// listStartupItems collects the items of every source; sources that fail are
+// reported but do not hide the others.
+func listStartupItems(sources []StartupSource) ([]StartupItem, []string) {
+	var items []StartupItem
+	var errs []string
+	for _, src := range sources {
+		list, err := src.List()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", src.Name(), err))
+			continue
+		}
+		items = append(items, list...)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Source != items[j].Source {
+			return items[i].Source < items[j].Source
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+	return items, errs
+}
+
+// ---------------------------------------------------------------------
+//  2) Startup Items UI
+// ---------------------------------------------------------------------
+
+func (s *FileScanner) setupStartupUI() fyne.CanvasObject {
+	sources := startupSources()
+	var items []StartupItem
+	changes, err := loadStartupChanges()
+	if err != nil {
+		fmt.Println("Error loading startup changes:", err)
+	}
+	selectedItem := -1
+	selectedChange := -1
+
+	statusLabel := widget.NewLabel("")
+
+	itemTable := widget.NewTable(
+		func() (int, int) {
+			return len(items) + 1, 4
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			if id.Row == 0 {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText([]string{"Enabled", "Name", "Source", "Command"}[id.Col])
+				return
+			}
+			it := items[id.Row-1]
+			label.TextStyle = fyne.TextStyle{}
+			switch id.Col {
+			case 0:
+				if it.Enabled {
+					label.SetText("Yes")
+				} else {
+					label.SetText("No")
+				}
+			case 1:
+				label.SetText(it.Name)
+			case 2:
+				label.SetText(it.Source)
+			case 3:
+				label.SetText(it.Command)
+			}
+		},
+	)
+	itemTable.SetColumnWidth(0, 80)
+	itemTable.SetColumnWidth(1, 250)
+	itemTable.SetColumnWidth(2, 160)
+	itemTable.SetColumnWidth(3, 500)
+	itemTable.OnSelected = func(id widget.TableCellID) {
+		selectedItem = id.Row - 1
+	}
+
+	changeList := widget.NewList(
+		func() int { return len(changes) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			// newest first
+			c := changes[len(changes)-1-id]
+			state := "disabled"
+			if c.Restored {
+				state = "restored"
+			}
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s  [%s] %s (%s)", c.Timestamp, state, c.Item.Name, c.Item.Source))
+		},
+	)
+	changeList.OnSelected = func(id widget.ListItemID) {
+		selectedChange = len(changes) - 1 - id
+	}
+
+	reload := func() {
+		var errs []string
+		items, errs = listStartupItems(sources)
+		selectedItem = -1
+		itemTable.UnselectAll()
+		itemTable.Refresh()
+		statusLabel.SetText(fmt.Sprintf("%d startup item(s)", len(items)))
+		if len(errs) > 0 {
+			statusLabel.SetText(statusLabel.Text + " — some sources could not be read: " + strings.Join(errs, "; "))
+		}
+	}
+
+	refreshBtn := s.commandButton(toolStartup, "Refresh", nil, reload)
+
+	disableBtn := s.commandButton(toolStartup, "Disable Selected", nil, func() {
+		if selectedItem < 0 || selectedItem >= len(items) {
+			dialog.ShowInformation("No Item Selected", "Please select a startup item.", s.mainWindow)
+			return
+		}
+		it := items[selectedItem]
+		if !it.Enabled {
+			dialog.ShowInformation("Already Disabled", it.Name+" is already disabled.", s.mainWindow)
+			return
+		}
+		src := findStartupSource(sources, it.Source)
+		if src == nil {
+			return
+		}
+		dialog.ShowConfirm("Confirm Disable", fmt.Sprintf("Disable %s (%s)?\nThis can be undone from the list below.", it.Name, it.Source), func(c bool) {
+			if !c {
+				return
+			}
+			undo, err := src.Disable(it)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to disable %s: %v", it.Name, err), s.mainWindow)
+				return
+			}
+			changes = append(changes, StartupChange{
+				Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+				Item:      it,
+				Undo:      undo,
+			})
+			if err := saveStartupChanges(changes); err != nil {
+				dialog.ShowError(err, s.mainWindow)
+			}
+			s.addDeletionRecord(it.Location, "Startup Items (disabled)")
+			s.refreshDeletionTable()
+			changeList.Refresh()
+			reload()
+		}, s.mainWindow)
+	})
+
+	restoreBtn := s.commandButton(toolStartup, "Re-enable Selected", nil, func() {
+		if selectedChange < 0 || selectedChange >= len(changes) {
+			dialog.ShowInformation("No Change Selected", "Please select a change to undo.", s.mainWindow)
+			return
+		}
+		c := &changes[selectedChange]
+		if c.Restored {
+			dialog.ShowInformation("Already Restored", c.Item.Name+" has already been re-enabled.", s.mainWindow)
+			return
+		}
+		src := findStartupSource(sources, c.Item.Source)
+		if src == nil {
+			dialog.ShowInformation("Not Available", c.Item.Source+" is not available on this system.", s.mainWindow)
+			return
+		}
+		if err := src.Restore(c.Item, c.Undo); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to re-enable %s: %v", c.Item.Name, err), s.mainWindow)
+			return
+		}
+		c.Restored = true
+		if err := saveStartupChanges(changes); err != nil {
+			dialog.ShowError(err, s.mainWindow)
+		}
+		s.addDeletionRecord(c.Item.Location, "Startup Items (re-enabled)")
+		s.refreshDeletionTable()
+		changeList.Refresh()
+		reload()
+	})
+
+	topBar := container.NewHBox(refreshBtn, disableBtn, layout.NewSpacer())
+	itemsBox := container.NewBorder(topBar, statusLabel, nil, nil, itemTable)
+	changesBox := container.NewBorder(
+		container.NewHBox(widget.NewLabel("Disabled by this tool"), restoreBtn, layout.NewSpacer()),
+		nil, nil, nil,
+		changeList,
+	)
+
+	reload()
+
+	split := container.NewVSplit(itemsBox, changesBox)
+	split.Offset = 0.7
+	return split
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func startupSources() []StartupSource {
+	return []StartupSource{
+		xdgAutostartSource{},
+		systemdSource{user: false},
+		systemdSource{user: true},
+	}
+}
+
+// ---------------------------------------------------------------------
+//  XDG Autostart (~/.config/autostart, /etc/xdg/autostart)
+// ---------------------------------------------------------------------
+
+type xdgAutostartSource struct{}
+
+func (xdgAutostartSource) Name() string { return "XDG Autostart" }
+
+func xdgUserAutostartDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "autostart")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "autostart")
+}
+
+func xdgSystemAutostartDirs() []string {
+	dirs := os.Getenv("XDG_CONFIG_DIRS")
+	if dirs == "" {
+		dirs = "/etc/xdg"
+	}
+	var res []string
+	for _, d := range filepath.SplitList(dirs) {
+		res = append(res, filepath.Join(d, "autostart"))
+	}
+	return res
+}
+
+// readDesktopEntry returns the keys of the [Desktop Entry] group.
+func readDesktopEntry(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys := map[string]string{}
+	inEntry := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		if !inEntry || line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			keys[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return keys, sc.Err()
+}
+
+func (xdgAutostartSource) List() ([]StartupItem, error) {
+	// A user file hides a system file of the same name, so collect system
+	// dirs first and let the user dir overwrite.
+	files := map[string]string{}
+	for _, dir := range append(xdgSystemAutostartDirs(), xdgUserAutostartDir()) {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.desktop"))
+		for _, m := range matches {
+			files[filepath.Base(m)] = m
+		}
+	}
+
+	var items []StartupItem
+	for id, path := range files {
+		keys, err := readDesktopEntry(path)
+		if err != nil {
+			continue
+		}
+		name := keys["Name"]
+		if name == "" {
+			name = strings.TrimSuffix(id, ".desktop")
+		}
+		enabled := !strings.EqualFold(keys["Hidden"], "true") &&
+			!strings.EqualFold(keys["X-GNOME-Autostart-enabled"], "false")
+		items = append(items, StartupItem{
+			Source:   "XDG Autostart",
+			ID:       id,
+			Name:     name,
+			Command:  keys["Exec"],
+			Location: path,
+			Enabled:  enabled,
+		})
+	}
+	return items, nil
+}
+
+// Disable writes a user override with Hidden=true. The undo token is the
+// previous content of the user file, or empty if there was none.
+func (xdgAutostartSource) Disable(item StartupItem) (string, error) {
+	userPath := filepath.Join(xdgUserAutostartDir(), item.ID)
+	undo := ""
+	if data, err := os.ReadFile(userPath); err == nil {
+		undo = "file:" + string(data)
+	}
+
+	src, err := os.ReadFile(item.Location)
+	if err != nil {
+		return "", err
+	}
+	var out []string
+	inEntry := false
+	for _, line := range strings.Split(string(src), "\n") {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "[") {
+			if inEntry {
+				out = append(out, "Hidden=true")
+			}
+			inEntry = trim == "[Desktop Entry]"
+		} else if inEntry && (strings.HasPrefix(trim, "Hidden=") || strings.HasPrefix(trim, "X-GNOME-Autostart-enabled=")) {
+			continue
+		}
+		out = append(out, line)
+	}
+	if inEntry {
+		out = append(out, "Hidden=true")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(userPath), 0755); err != nil {
+		return "", err
+	}
+	return undo, os.WriteFile(userPath, []byte(strings.Join(out, "\n")), 0644)
+}
+
+func (xdgAutostartSource) Restore(item StartupItem, undo string) error {
+	userPath := filepath.Join(xdgUserAutostartDir(), item.ID)
+	if strings.HasPrefix(undo, "file:") {
+		return os.WriteFile(userPath, []byte(strings.TrimPrefix(undo, "file:")), 0644)
+	}
+	err := os.Remove(userPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// ---------------------------------------------------------------------
+//  systemd Units
+// ---------------------------------------------------------------------
+
+type systemdSource struct {
+	user bool // --user units instead of system units
+}
+
+func (sd systemdSource) Name() string {
+	if sd.user {
+		return "systemd (user)"
+	}
+	return "systemd"
+}
+
+func (sd systemdSource) systemctl(args ...string) *exec.Cmd {
+	if sd.user {
+		args = append([]string{"--user"}, args...)
+	}
+	return exec.Command("systemctl", args...)
+}
+
+// List shows services that can be switched on or off at boot; static and
+// generated units cannot be disabled and are left out.
+func (sd systemdSource) List() ([]StartupItem, error) {
+	out, err := sd.systemctl("list-unit-files", "--type=service", "--no-legend", "--no-pager").Output()
+	if err != nil {
+		return nil, err
+	}
+	var items []StartupItem
+	sc := bufio.NewScanner(strings.NewReader(string(out)))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		unit, state := fields[0], fields[1]
+		if state != "enabled" && state != "disabled" {
+			continue
+		}
+		// template units (foo@.service) cannot be toggled without an instance
+		if strings.HasSuffix(unit, "@.service") {
+			continue
+		}
+		items = append(items, StartupItem{
+			Source:   sd.Name(),
+			ID:       unit,
+			Name:     strings.TrimSuffix(unit, ".service"),
+			Command:  "",
+			Location: unit,
+			Enabled:  state == "enabled",
+		})
+	}
+	return items, nil
+}
+
+func (sd systemdSource) Disable(item StartupItem) (string, error) {
+	if out, err := sd.systemctl("disable", item.ID).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return "enable", nil
+}
+
+func (sd systemdSource) Restore(item StartupItem, undo string) error {
+	if undo != "enable" {
+		return nil
+	}
+	if out, err := sd.systemctl("enable", item.ID).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows
+
+package main
+
+// startupSources has no implementation for this platform yet.
+func startupSources() []StartupSource {
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func startupSources() []StartupSource {
+	return []StartupSource{
+		registryRunSource{root: registry.CURRENT_USER, rootName: "HKCU"},
+		registryRunSource{root: registry.LOCAL_MACHINE, rootName: "HKLM"},
+		windowsServiceSource{},
+	}
+}
+
+// ---------------------------------------------------------------------
+//  Registry Run Keys
+// ---------------------------------------------------------------------
+
+const (
+	runKeyPath      = `Software\Microsoft\Windows\CurrentVersion\Run`
+	approvedKeyPath = `Software\Microsoft\Windows\CurrentVersion\Explorer\StartupApproved\Run`
+)
+
+// registryRunSource lists the Run key of one hive. Like Task Manager, it
+// disables entries through the StartupApproved key instead of deleting them.
+type registryRunSource struct {
+	root     registry.Key
+	rootName string
+}
+
+func (rs registryRunSource) Name() string { return "Registry Run (" + rs.rootName + ")" }
+
+func (rs registryRunSource) List() ([]StartupItem, error) {
+	k, err := registry.OpenKey(rs.root, runKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer k.Close()
+
+	names, err := k.ReadValueNames(0)
+	if err != nil {
+		return nil, err
+	}
+
+	approved, err := registry.OpenKey(rs.root, approvedKeyPath, registry.QUERY_VALUE)
+	haveApproved := err == nil
+	if haveApproved {
+		defer approved.Close()
+	}
+
+	var items []StartupItem
+	for _, name := range names {
+		cmd, _, err := k.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+		enabled := true
+		if haveApproved {
+			// first byte is even when enabled (0x02/0x06), odd when disabled (0x03/0x07)
+			if flags, _, err := approved.GetBinaryValue(name); err == nil && len(flags) > 0 {
+				enabled = flags[0]%2 == 0
+			}
+		}
+		items = append(items, StartupItem{
+			Source:   rs.Name(),
+			ID:       name,
+			Name:     name,
+			Command:  cmd,
+			Location: rs.rootName + `\` + runKeyPath + `\` + name,
+			Enabled:  enabled,
+		})
+	}
+	return items, nil
+}
+
+// Disable marks the value disabled in StartupApproved. The undo token is the
+// previous StartupApproved value in hex, or empty if there was none.
+func (rs registryRunSource) Disable(item StartupItem) (string, error) {
+	k, _, err := registry.CreateKey(rs.root, approvedKeyPath, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer k.Close()
+
+	undo := ""
+	if prev, _, err := k.GetBinaryValue(item.ID); err == nil {
+		undo = "hex:" + hex.EncodeToString(prev)
+	}
+	flags := make([]byte, 12)
+	flags[0] = 0x03
+	ft := windows.NsecToFiletime(time.Now().UnixNano())
+	for i := 0; i < 4; i++ {
+		flags[4+i] = byte(ft.LowDateTime >> (8 * i))
+		flags[8+i] = byte(ft.HighDateTime >> (8 * i))
+	}
+	return undo, k.SetBinaryValue(item.ID, flags)
+}
+
+func (rs registryRunSource) Restore(item StartupItem, undo string) error {
+	k, err := registry.OpenKey(rs.root, approvedKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	if len(undo) > 4 && undo[:4] == "hex:" {
+		prev, err := hex.DecodeString(undo[4:])
+		if err != nil {
+			return err
+		}
+		return k.SetBinaryValue(item.ID, prev)
+	}
+	err = k.DeleteValue(item.ID)
+	if err == registry.ErrNotExist {
+		return nil
+	}
+	return err
+}
+
+// ---------------------------------------------------------------------
+//  Windows Services
+// ---------------------------------------------------------------------
+
+type windowsServiceSource struct{}
+
+// connectServices opens the service control manager with only the given
+// rights; mgr.Connect asks for full access, which needs elevation.
+func connectServices(access uint32) (*mgr.Mgr, error) {
+	h, err := windows.OpenSCManager(nil, nil, access)
+	if err != nil {
+		return nil, err
+	}
+	return &mgr.Mgr{Handle: h}, nil
+}
+
+// openService opens a service with only the given rights, where
+// m.OpenService would ask for SERVICE_ALL_ACCESS.
+func openService(m *mgr.Mgr, name string, access uint32) (*mgr.Service, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	h, err := windows.OpenService(m.Handle, p, access)
+	if err != nil {
+		return nil, err
+	}
+	return &mgr.Service{Name: name, Handle: h}, nil
+}
+
+func (windowsServiceSource) Name() string { return "Windows Services" }
+
+// List shows automatic and disabled services; manual ones do not start at boot.
+func (ws windowsServiceSource) List() ([]StartupItem, error) {
+	m, err := connectServices(windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+
+	names, err := m.ListServices()
+	if err != nil {
+		return nil, err
+	}
+	var items []StartupItem
+	for _, name := range names {
+		svc, err := openService(m, name, windows.SERVICE_QUERY_CONFIG)
+		if err != nil {
+			continue
+		}
+		cfg, err := svc.Config()
+		svc.Close()
+		if err != nil {
+			continue
+		}
+		if cfg.StartType != mgr.StartAutomatic && cfg.StartType != mgr.StartDisabled {
+			continue
+		}
+		display := cfg.DisplayName
+		if display == "" {
+			display = name
+		}
+		items = append(items, StartupItem{
+			Source:   ws.Name(),
+			ID:       name,
+			Name:     display,
+			Command:  cfg.BinaryPathName,
+			Location: `Service\` + name,
+			Enabled:  cfg.StartType == mgr.StartAutomatic,
+		})
+	}
+	return items, nil
+}
+
+// Disable sets the start type to Disabled. The undo token is the previous start type.
+func (windowsServiceSource) Disable(item StartupItem) (string, error) {
+	m, err := connectServices(windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return "", err
+	}
+	defer m.Disconnect()
+
+	svc, err := openService(m, item.ID, windows.SERVICE_QUERY_CONFIG|windows.SERVICE_CHANGE_CONFIG)
+	if err != nil {
+		return "", err
+	}
+	defer svc.Close()
+
+	cfg, err := svc.Config()
+	if err != nil {
+		return "", err
+	}
+	undo := strconv.FormatUint(uint64(cfg.StartType), 10)
+	cfg.StartType = mgr.StartDisabled
+	return undo, svc.UpdateConfig(cfg)
+}
+
+func (windowsServiceSource) Restore(item StartupItem, undo string) error {
+	startType, err := strconv.ParseUint(undo, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid undo record %q", undo)
+	}
+	m, err := connectServices(windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	svc, err := openService(m, item.ID, windows.SERVICE_QUERY_CONFIG|windows.SERVICE_CHANGE_CONFIG)
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	cfg, err := svc.Config()
+	if err != nil {
+		return err
+	}
+	cfg.StartType = uint32(startType)
+	return svc.UpdateConfig(cfg)
+}
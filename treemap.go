@@ -0,0 +1,329 @@
+package main
+
+import (
+	"image/color"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ---------------------------------------------------------------------
+//  1) Directory Size Tree
+// ---------------------------------------------------------------------
+
+// DirNode is a directory with the total size of everything below it.
+// Files directly inside a directory are summed into FilesSize.
+type DirNode struct {
+	Name      string
+	Path      string
+	Size      int64
+	FilesSize int64
+	FileCount int
+	Parent    *DirNode
+	Children  map[string]*DirNode
+}
+
+func newDirNode(name, path string, parent *DirNode) *DirNode {
+	return &DirNode{Name: name, Path: path, Parent: parent, Children: map[string]*DirNode{}}
+}
+
+// newDirTree creates the root for a scan. With several scanned directories
+// the root is a synthetic node holding one child per directory.
+func newDirTree(dirs []string) *DirNode {
+	if len(dirs) == 1 {
+		return newDirNode(dirs[0], dirs[0], nil)
+	}
+	root := newDirNode("All scanned directories", "", nil)
+	for _, d := range dirs {
+		root.Children[d] = newDirNode(d, d, root)
+	}
+	return root
+}
+
+// addFile adds a file found while scanning scanDir to the tree.
+func (n *DirNode) addFile(scanDir, path string, size int64) {
+	node := n
+	if n.Path != scanDir {
+		node = n.Children[scanDir]
+		if node == nil {
+			return
+		}
+		n.Size += size
+	}
+	rel, err := filepath.Rel(scanDir, filepath.Dir(path))
+	if err != nil {
+		return
+	}
+	node.Size += size
+	if rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			child := node.Children[part]
+			if child == nil {
+				child = newDirNode(part, filepath.Join(node.Path, part), node)
+				node.Children[part] = child
+			}
+			child.Size += size
+			node = child
+		}
+	}
+	node.FilesSize += size
+	node.FileCount++
+}
+
+// ---------------------------------------------------------------------
+//  2) Squarified Layout
+// ---------------------------------------------------------------------
+
+type treemapCell struct {
+	node  *DirNode // nil for the "files in this folder" cell
+	label string
+	size  int64
+	pos   fyne.Position
+	dim   fyne.Size
+}
+
+// squarify lays out the cells (sorted by size, largest first) in the given
+// rectangle, keeping aspect ratios close to 1 (Bruls, Huizing, van Wijk).
+func squarify(cells []treemapCell, pos fyne.Position, size fyne.Size) {
+	var total float64
+	for _, c := range cells {
+		total += float64(c.size)
+	}
+	if total == 0 || size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+	scale := float64(size.Width) * float64(size.Height) / total
+
+	x, y := float64(pos.X), float64(pos.Y)
+	w, h := float64(size.Width), float64(size.Height)
+
+	worst := func(row []treemapCell, side float64) float64 {
+		var sum, max, min float64
+		min = -1
+		for _, c := range row {
+			a := float64(c.size) * scale
+			sum += a
+			if a > max {
+				max = a
+			}
+			if min < 0 || a < min {
+				min = a
+			}
+		}
+		if sum == 0 || min <= 0 {
+			return 1e18
+		}
+		s2, side2 := sum*sum, side*side
+		r1 := side2 * max / s2
+		r2 := s2 / (side2 * min)
+		if r1 > r2 {
+			return r1
+		}
+		return r2
+	}
+
+	start := 0
+	for start < len(cells) {
+		side := w
+		if h < w {
+			side = h
+		}
+		end := start + 1
+		for end < len(cells) && worst(cells[start:end+1], side) <= worst(cells[start:end], side) {
+			end++
+		}
+
+		row := cells[start:end]
+		var rowArea float64
+		for _, c := range row {
+			rowArea += float64(c.size) * scale
+		}
+		thickness := rowArea / side
+		offset := 0.0
+		for i := range row {
+			length := float64(row[i].size) * scale / thickness
+			if w >= h {
+				// lay the row out as a column on the left
+				row[i].pos = fyne.NewPos(float32(x), float32(y+offset))
+				row[i].dim = fyne.NewSize(float32(thickness), float32(length))
+			} else {
+				// lay the row out along the top
+				row[i].pos = fyne.NewPos(float32(x+offset), float32(y))
+				row[i].dim = fyne.NewSize(float32(length), float32(thickness))
+			}
+			offset += length
+		}
+		if w >= h {
+			x += thickness
+			w -= thickness
+		} else {
+			y += thickness
+			h -= thickness
+		}
+		start = end
+	}
+}
+
+// ---------------------------------------------------------------------
+//  3) Treemap Widget
+// ---------------------------------------------------------------------
+
+var treemapPalette = []color.RGBA{
+	{R: 66, G: 133, B: 244, A: 255},
+	{R: 219, G: 68, B: 55, A: 255},
+	{R: 244, G: 180, B: 0, A: 255},
+	{R: 15, G: 157, B: 88, A: 255},
+	{R: 171, G: 71, B: 188, A: 255},
+	{R: 0, G: 172, B: 193, A: 255},
+	{R: 255, G: 112, B: 67, A: 255},
+	{R: 158, G: 157, B: 36, A: 255},
+}
+
+// Treemap shows the children of one DirNode as nested rectangles sized by
+// disk usage. Tapping a folder drills into it.
+type Treemap struct {
+	widget.BaseWidget
+
+	current *DirNode
+	cells   []treemapCell
+
+	// OnNodeSelected is called with the node shown after a tap or Up.
+	OnNodeSelected func(*DirNode)
+}
+
+func newTreemap() *Treemap {
+	tm := &Treemap{}
+	tm.ExtendBaseWidget(tm)
+	return tm
+}
+
+func (tm *Treemap) setRoot(root *DirNode) {
+	tm.current = root
+	tm.Refresh()
+}
+
+// up shows the parent of the current node.
+func (tm *Treemap) up() {
+	if tm.current == nil || tm.current.Parent == nil {
+		return
+	}
+	tm.current = tm.current.Parent
+	tm.Refresh()
+	if tm.OnNodeSelected != nil {
+		tm.OnNodeSelected(tm.current)
+	}
+}
+
+func (tm *Treemap) Tapped(ev *fyne.PointEvent) {
+	for _, c := range tm.cells {
+		if c.node == nil {
+			continue
+		}
+		if ev.Position.X >= c.pos.X && ev.Position.X < c.pos.X+c.dim.Width &&
+			ev.Position.Y >= c.pos.Y && ev.Position.Y < c.pos.Y+c.dim.Height {
+			tm.current = c.node
+			tm.Refresh()
+			if tm.OnNodeSelected != nil {
+				tm.OnNodeSelected(c.node)
+			}
+			return
+		}
+	}
+}
+
+func (tm *Treemap) CreateRenderer() fyne.WidgetRenderer {
+	return &treemapRenderer{tm: tm}
+}
+
+type treemapRenderer struct {
+	tm      *Treemap
+	objects []fyne.CanvasObject
+	size    fyne.Size
+}
+
+func (r *treemapRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.rebuild()
+}
+
+func (r *treemapRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(300, 300)
+}
+
+func (r *treemapRenderer) Refresh() {
+	r.rebuild()
+	canvas.Refresh(r.tm)
+}
+
+func (r *treemapRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *treemapRenderer) Destroy() {}
+
+func (r *treemapRenderer) rebuild() {
+	tm := r.tm
+	r.objects = nil
+	tm.cells = nil
+	if tm.current == nil {
+		msg := canvas.NewText("Scan a directory to see its disk usage.", theme.Color(theme.ColorNameDisabled))
+		msg.Move(fyne.NewPos(theme.Padding(), theme.Padding()))
+		r.objects = append(r.objects, msg)
+		return
+	}
+
+	for _, child := range tm.current.Children {
+		if child.Size > 0 {
+			tm.cells = append(tm.cells, treemapCell{node: child, label: child.Name, size: child.Size})
+		}
+	}
+	if tm.current.FilesSize > 0 {
+		tm.cells = append(tm.cells, treemapCell{label: "(files)", size: tm.current.FilesSize})
+	}
+	sort.Slice(tm.cells, func(i, j int) bool { return tm.cells[i].size > tm.cells[j].size })
+	squarify(tm.cells, fyne.NewPos(0, 0), r.size)
+
+	textSize := theme.CaptionTextSize()
+	for i, c := range tm.cells {
+		fill := treemapPalette[i%len(treemapPalette)]
+		if c.node == nil {
+			fill = color.RGBA{R: 110, G: 110, B: 110, A: 255}
+		}
+		rect := canvas.NewRectangle(fill)
+		rect.StrokeColor = theme.Color(theme.ColorNameBackground)
+		rect.StrokeWidth = 1
+		rect.Move(c.pos)
+		rect.Resize(c.dim)
+		r.objects = append(r.objects, rect)
+
+		if c.dim.Width < 60 || c.dim.Height < 2*textSize+4 {
+			continue
+		}
+		name := canvas.NewText(fitLabel(c.label, c.dim.Width-8, textSize), color.White)
+		name.TextSize = textSize
+		name.TextStyle = fyne.TextStyle{Bold: true}
+		name.Move(fyne.NewPos(c.pos.X+4, c.pos.Y+2))
+		sizeText := canvas.NewText(formatSize(c.size), color.White)
+		sizeText.TextSize = textSize
+		sizeText.Move(fyne.NewPos(c.pos.X+4, c.pos.Y+4+textSize))
+		r.objects = append(r.objects, name, sizeText)
+	}
+}
+
+// fitLabel shortens text so it roughly fits in width at the given text size.
+func fitLabel(text string, width, textSize float32) string {
+	maxChars := int(width / (textSize * 0.6))
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	if maxChars <= 1 {
+		return ""
+	}
+	return string(runes[:maxChars-1]) + "…"
+}
@@ -0,0 +1,67 @@
+package main
+
+import "sync"
+
+// ---------------------------------------------------------------------
+//  1) UI Update Queue
+// ---------------------------------------------------------------------
+
+// Background goroutines (scans, scheduled jobs, alert rules) never touch
+// widgets or the scan results directly. They do their work on local data
+// and hand the result to postUI; runUIUpdates passes those updates, in
+// order, to the goroutine that runs the main window's callbacks, so they
+// never run alongside a button handler. The scan results themselves are
+// guarded by FileScanner.mu, which is never held while calling into a
+// widget: list callbacks take it, and Refresh calls them on the same
+// goroutine.
+
+// uiQueue holds updates that have been posted but not yet handed on. It has
+// no limit, so postUI never blocks a background goroutine.
+type uiQueue struct {
+	mu      sync.Mutex
+	pending []func()
+	wake    chan struct{} // signalled when pending becomes non-empty
+}
+
+func newUIQueue() *uiQueue {
+	return &uiQueue{wake: make(chan struct{}, 1)}
+}
+
+// eventQueue is implemented by Fyne's desktop and mobile windows. Functions
+// queued on it run one at a time on the goroutine that handles the window's
+// input events and callbacks.
+type eventQueue interface {
+	QueueEvent(fn func())
+}
+
+// postUI queues fn to run on the UI goroutine.
+func (s *FileScanner) postUI(fn func()) {
+	q := s.uiUpdates
+	q.mu.Lock()
+	q.pending = append(q.pending, fn)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default: // already signalled
+	}
+}
+
+// runUIUpdates hands queued updates to the main window's event goroutine.
+// Windows without an event queue (the test driver) get them run here, one
+// at a time.
+func (s *FileScanner) runUIUpdates() {
+	q := s.uiUpdates
+	run := func(fn func()) { fn() }
+	if w, ok := s.mainWindow.(eventQueue); ok {
+		run = w.QueueEvent
+	}
+	for range q.wake {
+		q.mu.Lock()
+		pending := q.pending
+		q.pending = nil
+		q.mu.Unlock()
+		for _, fn := range pending {
+			run(fn)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// newTestScanner builds a FileScanner with the Duplicate Finder on a test
+// window, working in a temporary directory so no data files are written to
+// the source tree.
+func newTestScanner(t *testing.T) *FileScanner {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	test.NewApp()
+	w := test.NewWindow(nil)
+	s := &FileScanner{
+		allDuplicates:  map[string][]string{},
+		mainWindow:     w,
+		settings:       defaultSettings(),
+		largeFileItems: []*LargeFileItem{},
+		uiUpdates:      newUIQueue(),
+	}
+	go s.runUIUpdates()
+	t.Cleanup(func() { flushUI(t, s) })
+	s.leftNav = s.makeLeftMenu()
+	s.duplicateFinderRoot = s.setupDuplicateFinderUI()
+	w.SetContent(s.duplicateFinderRoot)
+	return s
+}
+
+// flushUI waits until every update posted so far has run.
+func flushUI(t *testing.T, s *FileScanner) {
+	t.Helper()
+	done := make(chan struct{})
+	s.postUI(func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("queued updates did not run")
+	}
+}
+
+func TestPostUIDoesNotBlock(t *testing.T) {
+	s := &FileScanner{uiUpdates: newUIQueue()}
+	const n = 1000 // far more than a fixed buffer would hold
+	var got []int
+	for i := 0; i < n; i++ {
+		i := i
+		s.postUI(func() { got = append(got, i) }) // nothing runs the queue yet
+	}
+
+	go s.runUIUpdates()
+	flushUI(t, s)
+	if len(got) != n {
+		t.Fatalf("%d of %d updates ran", len(got), n)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("update %d ran as number %d", v, i)
+		}
+	}
+}
+
+// TestScanResultDelivery runs a duplicate scan while the test goroutine
+// reads the results the way list callbacks do. Run with -race.
+func TestScanResultDelivery(t *testing.T) {
+	s := newTestScanner(t)
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		content := []byte(fmt.Sprintf("content %d", i%5))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%02d.txt", i)), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitForDuplicates := func(wantItems, wantGroups int) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			s.mu.Lock()
+			items, groups := len(s.allFileItems), len(s.allDuplicates)
+			s.mu.Unlock()
+			if items == wantItems && groups == wantGroups {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("got %d files in %d groups, want %d in %d", items, groups, wantItems, wantGroups)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	s.showScanningDuplicates([]ScanRoot{{Path: dir}}, FileFilter{}, false)
+	waitForDuplicates(20, 5)
+
+	// a second scan replaces the first one's results
+	if err := os.WriteFile(filepath.Join(dir, "extra.txt"), []byte("content 0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.showScanningDuplicates([]ScanRoot{{Path: dir}}, FileFilter{}, false)
+	waitForDuplicates(21, 5)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const compareChunkSize = 64 << 10 // Bytes read from each file per comparison step
+
+// ---------------------------------------------------------------------
+//  1) Scan State
+// ---------------------------------------------------------------------
+
+// stampModTimes records the modification time of every listed file, so
+// verification can tell whether it changed after the scan.
+func stampModTimes(items []*FileItem) {
+	for _, fi := range items {
+		if fi.archive != "" {
+			continue
+		}
+		if st, err := os.Stat(fi.filePath); err == nil {
+			fi.modTime = st.ModTime()
+		}
+	}
+}
+
+// checkUnchanged fails if path's size or modification time differ from
+// what the scan recorded. A folder's size is its total size.
+func checkUnchanged(path string, size int64, modTime time.Time) error {
+	st, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	cur := st.Size()
+	if st.IsDir() {
+		cur = treeSize(path)
+	}
+	if cur != size || (!modTime.IsZero() && !st.ModTime().Equal(modTime)) {
+		return fmt.Errorf("%s changed since the scan", path)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------
+//  2) Byte-for-Byte Comparison
+// ---------------------------------------------------------------------
+
+// sameReaders reports whether a and b yield the same bytes.
+func sameReaders(a, b io.Reader) (bool, error) {
+	bufA := make([]byte, compareChunkSize)
+	bufB := make([]byte, compareChunkSize)
+	for {
+		na, errA := io.ReadFull(a, bufA)
+		nb, errB := io.ReadFull(b, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		endA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		endB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if errA != nil && !endA {
+			return false, errA
+		}
+		if errB != nil && !endB {
+			return false, errB
+		}
+		if endA || endB {
+			return endA == endB, nil
+		}
+	}
+}
+
+// withContent calls fn with the content of a file on disk, or of the
+// virtual path inside archive if archive is set.
+func withContent(path, archive string, fn func(io.Reader) error) error {
+	if archive != "" {
+		return compareArchiveEntry(archive, path, fn)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(f)
+}
+
+// sameFileContent compares two files byte by byte. archives maps the
+// virtual paths of files inside archives to the archive holding them.
+func sameFileContent(a, b string, archives map[string]string) (bool, error) {
+	var same bool
+	err := withContent(a, archives[a], func(ra io.Reader) error {
+		return withContent(b, archives[b], func(rb io.Reader) error {
+			var err error
+			same, err = sameReaders(ra, rb)
+			return err
+		})
+	})
+	return same, err
+}
+
+// coveredBy checks that every file of candidate (a file or a folder) has a
+// byte-identical counterpart in kept, so deleting candidate loses nothing.
+// kept may be a file inside an archive listed in archives.
+func coveredBy(candidate, kept string, archives map[string]string) error {
+	st, err := os.Stat(candidate)
+	if err != nil {
+		return err
+	}
+	if !st.IsDir() {
+		same, err := sameFileContent(candidate, kept, archives)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return fmt.Errorf("%s differs from %s", candidate, kept)
+		}
+		return nil
+	}
+	return filepath.WalkDir(candidate, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", p)
+		}
+		rel, _ := filepath.Rel(candidate, p)
+		same, err := sameFileContent(p, filepath.Join(kept, rel), nil)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return fmt.Errorf("%s differs from the copy in %s", p, kept)
+		}
+		return nil
+	})
+}
+
+// ---------------------------------------------------------------------
+//  3) Verifying Before Deletion
+// ---------------------------------------------------------------------
+
+// keepCopies runs before every Duplicate Finder deletion. Groups whose
+// every copy would be deleted are left alone. If some paths are in no group
+// of the current scan, as in a saved plan from an earlier scan, nothing is
+// deleted, since no kept copy can be checked for them.
+func (s *FileScanner) keepCopies(paths []string) (ok []string, problems []string) {
+	deleting := make(map[string]bool)
+	for _, p := range paths {
+		deleting[p] = true
+	}
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.allDuplicates))
+	for key := range s.allDuplicates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	grouped := make(map[string]bool)
+	for _, key := range keys {
+		var cands []string
+		var kept int
+		for _, p := range s.allDuplicates[key] {
+			if deleting[p] {
+				cands = append(cands, p)
+				grouped[p] = true
+			} else {
+				kept++
+			}
+		}
+		if len(cands) == 0 {
+			continue
+		}
+		if kept == 0 {
+			problems = append(problems, fmt.Sprintf("Skipped %d file(s): no copy would be kept", len(cands)))
+			continue
+		}
+		ok = append(ok, cands...)
+	}
+	s.mu.Unlock()
+
+	var missing int
+	for _, p := range paths {
+		if !grouped[p] {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return nil, []string{fmt.Sprintf("%d file(s) are not in the current scan's results, so nothing was deleted. Scan again first.", missing)}
+	}
+	return ok, problems
+}
+
+// verifyDuplicates runs right before Duplicate Finder deletes paths when
+// asked to. After keepCopies, it checks for each group that nothing changed
+// since the scan and that every file to delete is byte for byte the same as
+// a kept copy. A group that fails is left alone entirely. Groups of similar
+// images or media are not compared by content, as their files are not
+// meant to be identical.
+func (s *FileScanner) verifyDuplicates(paths []string) (ok []string, problems []string) {
+	paths, problems = s.keepCopies(paths)
+	deleting := make(map[string]bool)
+	for _, p := range paths {
+		deleting[p] = true
+	}
+	items := make(map[string]FileItem)
+	archives := make(map[string]string)
+	groups := make(map[string][]string)
+	s.mu.Lock()
+	for _, fi := range s.allFileItems {
+		items[fi.filePath] = *fi
+		if fi.archive != "" {
+			archives[fi.filePath] = fi.archive
+		}
+	}
+	for key, g := range s.allDuplicates {
+		groups[key] = append([]string(nil), g...)
+	}
+	s.mu.Unlock()
+	unchanged := func(p string) error {
+		if archives[p] != "" {
+			return nil // compared by content below
+		}
+		return checkUnchanged(p, items[p].size, items[p].modTime)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var cands, kept []string
+		for _, p := range groups[key] {
+			if deleting[p] {
+				cands = append(cands, p)
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		if len(cands) == 0 {
+			continue
+		}
+		err := func() error {
+			for _, c := range cands {
+				if err := unchanged(c); err != nil {
+					return err
+				}
+			}
+			if strings.HasPrefix(key, similarGroupPrefix) || strings.HasPrefix(key, mediaGroupPrefix) {
+				return nil // nothing identical to compare with
+			}
+			var keep string
+			for _, k := range kept {
+				if unchanged(k) == nil {
+					keep = k
+					break
+				}
+			}
+			if keep == "" {
+				return fmt.Errorf("no unchanged copy of %s is left to keep", cands[0])
+			}
+			for _, c := range cands {
+				if err := coveredBy(c, keep, archives); err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Skipped %d file(s): %v", len(cands), err))
+			continue
+		}
+		ok = append(ok, cands...)
+	}
+	return ok, problems
+}
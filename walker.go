@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// WalkOptions control how scans treat links and mount points.
+type WalkOptions struct {
+	FollowLinks    bool // enter symbolic links and junctions
+	SameFilesystem bool // do not cross into other file systems
+}
+
+func (s *FileScanner) walkOptions() WalkOptions {
+	return WalkOptions{FollowLinks: s.settings.FollowLinks, SameFilesystem: s.settings.SameFilesystem}
+}
+
+// SkippedPath is a file or folder a scan could not read or had to leave out.
+type SkippedPath struct {
+	Path   string
+	Reason string
+}
+
+const linkNotFollowed = "link not followed" // Reason of links left out by WalkOptions
+
+// ---------------------------------------------------------------------
+//  1) Walking
+// ---------------------------------------------------------------------
+
+type treeWalker struct {
+	opts    WalkOptions
+	fn      func(p string, info os.FileInfo) error
+	root    string   // root with links resolved
+	rootFS  string   // file system of the root
+	linked  []string // folders entered through links, resolved
+	skipped []SkippedPath
+}
+
+// walkTree calls fn for root and everything below it, each folder before
+// its contents. Unlike filepath.Walk it goes on past entries it cannot read
+// and returns them as skipped; only an unreadable root is an error. fn may
+// return filepath.SkipDir for a folder; any other error stops the walk.
+//
+// Links are listed as skipped unless opts.FollowLinks is set. A followed link is
+// skipped if it points back into a folder the walk covers, so loops end and
+// no file is listed twice. Only regular files are passed to fn.
+func walkTree(root string, opts WalkOptions, fn func(p string, info os.FileInfo) error) ([]SkippedPath, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	real, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	w := &treeWalker{opts: opts, fn: fn, root: real, rootFS: fileSystemID(real, info)}
+	if !info.IsDir() {
+		if err := fn(root, info); err != nil && err != filepath.SkipDir {
+			return nil, err
+		}
+		return nil, nil
+	}
+	err = w.walkDir(root, real, info)
+	return w.skipped, err
+}
+
+func (w *treeWalker) skip(p string, err error) {
+	w.skipped = append(w.skipped, skippedPath(p, err))
+}
+
+// skippedPath records p as skipped because of err, without repeating the
+// path in the reason.
+func skippedPath(p string, err error) SkippedPath {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		err = pe.Err
+	}
+	return SkippedPath{Path: p, Reason: err.Error()}
+}
+
+// walkDir walks the folder dir, whose resolved path is real.
+func (w *treeWalker) walkDir(dir, real string, info os.FileInfo) error {
+	if err := w.fn(dir, info); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.skip(dir, err) // entries read before the error are still walked
+	}
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		pReal := filepath.Join(real, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			w.skip(p, err)
+			continue
+		}
+		if isLink(p, info) {
+			if !w.opts.FollowLinks {
+				w.skipped = append(w.skipped, SkippedPath{Path: p, Reason: linkNotFollowed})
+				continue
+			}
+			if info, pReal, err = w.follow(p, real); err != nil {
+				w.skip(p, err)
+				continue
+			}
+		}
+		if info.IsDir() {
+			if w.opts.SameFilesystem && fileSystemID(pReal, info) != w.rootFS {
+				w.skip(p, fmt.Errorf("on another file system"))
+				continue
+			}
+			if err := w.walkDir(p, pReal, info); err != nil {
+				return err
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			continue // devices, pipes and sockets
+		}
+		if err := w.fn(p, info); err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+	return nil
+}
+
+// follow resolves the link p inside the folder whose resolved path is
+// parent, refusing targets the walk already covers.
+func (w *treeWalker) follow(p, parent string) (os.FileInfo, string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, "", errors.New("broken link")
+	}
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.IsDir() && isUnder(parent, real) {
+		return nil, "", fmt.Errorf("link loop to %s", real)
+	}
+	if isUnder(real, w.root) {
+		return nil, "", fmt.Errorf("links to %s, which is scanned anyway", real)
+	}
+	for _, d := range w.linked {
+		if isUnder(real, d) {
+			return nil, "", fmt.Errorf("links to %s, which was scanned through another link", real)
+		}
+	}
+	if info.IsDir() {
+		w.linked = append(w.linked, real)
+	}
+	return info, real, nil
+}
+
+// ---------------------------------------------------------------------
+//  2) Skipped Paths
+// ---------------------------------------------------------------------
+
+// showScanResult shows a scan's summary, with the paths it skipped if
+// there were any.
+func (s *FileScanner) showScanResult(title, msg string, skipped []SkippedPath) {
+	if len(skipped) == 0 {
+		dialog.ShowInformation(title, msg, s.mainWindow)
+		return
+	}
+	msg += fmt.Sprintf("\n%d path(s) were not scanned:", len(skipped))
+	list := widget.NewList(
+		func() int { return len(skipped) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(skipped[id].Path + " — " + skipped[id].Reason)
+		},
+	)
+	dlg := dialog.NewCustom(title, "OK", container.NewBorder(widget.NewLabel(msg), nil, nil, nil, list), s.mainWindow)
+	dlg.Resize(fyne.NewSize(700, 400))
+	dlg.Show()
+}
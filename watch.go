@@ -0,0 +1,525 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchBatchInterval = time.Second // changes are collected this long before results are updated
+
+// ---------------------------------------------------------------------
+//  1) Watching Folders
+// ---------------------------------------------------------------------
+
+// resultWatcher watches the folders of a scan and passes the paths that
+// changed to apply, in batches. fsnotify only watches single folders, so
+// every subfolder is added, including those created later.
+type resultWatcher struct {
+	w      *fsnotify.Watcher
+	dirs   []string   // roots, added when run starts
+	filter FileFilter // folder rules of the scan
+	opts   WalkOptions
+	apply  func(changed []string)
+	stop   chan struct{}
+}
+
+// startWatcher prepares a watcher for dirs. Their folders are only added
+// once run starts, as walking large trees takes a while.
+func startWatcher(dirs []string, filter FileFilter, opts WalkOptions) (*resultWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &resultWatcher{w: w, dirs: dirs, filter: filter.folderRules(), opts: opts, stop: make(chan struct{})}, nil
+}
+
+// addTree watches dir and its subfolders. Files already inside are added
+// to found, as a folder may be moved in with its contents. It returns how
+// many folders could not be watched, and the last error. Once the system
+// limit on watches is reached the rest of the tree is not tried.
+func (rw *resultWatcher) addTree(dir string, found map[string]bool) (failed int, err error) {
+	walkTree(dir, rw.opts, func(p string, info os.FileInfo) error {
+		if info.IsDir() {
+			if p != dir && rw.filter.skipDir(p, info) {
+				return filepath.SkipDir
+			}
+			if e := rw.w.Add(p); e != nil {
+				failed, err = failed+1, e
+				if errors.Is(e, syscall.ENOSPC) {
+					return e
+				}
+			}
+			return nil
+		}
+		if found != nil {
+			found[p] = true
+		}
+		return nil
+	})
+	return failed, err
+}
+
+// reportWatchErrors prints one line for the folders addTree could not
+// watch.
+func reportWatchErrors(failed int, err error) {
+	switch {
+	case failed == 0:
+	case errors.Is(err, syscall.ENOSPC):
+		fmt.Println("Error watching folders: the system limit on watched folders was reached, so changes in some folders are not noticed")
+	default:
+		fmt.Printf("Error watching folders: %d folder(s) could not be watched: %v\n", failed, err)
+	}
+}
+
+// run adds the watched folders, then collects events until close is
+// called. apply runs on this goroutine.
+func (rw *resultWatcher) run() {
+	ticker := time.NewTicker(watchBatchInterval)
+	defer ticker.Stop()
+	defer rw.w.Close()
+	var failed int
+	var err error
+	for _, d := range rw.dirs {
+		select {
+		case <-rw.stop:
+			return
+		default:
+		}
+		n, e := rw.addTree(d, nil)
+		if n > 0 {
+			failed, err = failed+n, e
+		}
+		if errors.Is(e, syscall.ENOSPC) {
+			break
+		}
+	}
+	reportWatchErrors(failed, err)
+	pending := make(map[string]bool)
+	for {
+		select {
+		case <-rw.stop:
+			return
+		case ev, ok := <-rw.w.Events:
+			if !ok {
+				return
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			pending[ev.Name] = true
+			if ev.Has(fsnotify.Create) {
+				if st, err := os.Lstat(ev.Name); err == nil && st.IsDir() && !rw.filter.skipDir(ev.Name, st) {
+					reportWatchErrors(rw.addTree(ev.Name, pending))
+				}
+			}
+		case err, ok := <-rw.w.Errors:
+			if !ok {
+				return
+			}
+			fmt.Println("Error watching files:", err)
+		case <-ticker.C:
+			if len(pending) == 0 {
+				continue
+			}
+			changed := make([]string, 0, len(pending))
+			for p := range pending {
+				changed = append(changed, p)
+			}
+			sort.Strings(changed)
+			pending = make(map[string]bool)
+			rw.apply(changed)
+		}
+	}
+}
+
+func (rw *resultWatcher) close() {
+	close(rw.stop)
+}
+
+// ---------------------------------------------------------------------
+//  2) Duplicate Finder
+// ---------------------------------------------------------------------
+
+// hashIndex holds the hash-size key of every file an exact-duplicate scan
+// compared, with or without copies, so files that appear later can find
+// theirs.
+type hashIndex struct {
+	keys     map[string]string   // path -> key
+	paths    map[string][]string // key -> paths
+	archives map[string]string   // virtual path of a file inside an archive -> archive
+	algo     string
+	verify   bool // check new matches with SHA-256, as the scan did
+	filter   FileFilter
+}
+
+func newHashIndex(h map[string][]string, archives map[string]string, algo string, verify bool, filter FileFilter) *hashIndex {
+	idx := &hashIndex{keys: make(map[string]string), paths: make(map[string][]string), archives: archives, algo: algo, verify: verify, filter: filter}
+	for key, paths := range h {
+		for _, p := range paths {
+			idx.add(p, key)
+		}
+	}
+	return idx
+}
+
+func (idx *hashIndex) add(p, key string) {
+	idx.keys[p] = key
+	idx.paths[key] = append(idx.paths[key], p)
+}
+
+func (idx *hashIndex) remove(p string) {
+	key, ok := idx.keys[p]
+	if !ok {
+		return
+	}
+	delete(idx.keys, p)
+	delete(idx.archives, p)
+	paths := idx.paths[key]
+	for i, q := range paths {
+		if q == p {
+			idx.paths[key] = append(paths[:i:i], paths[i+1:]...)
+			break
+		}
+	}
+	if len(idx.paths[key]) == 0 {
+		delete(idx.paths, key)
+	}
+}
+
+// watchDuplicates starts or stops watching the Duplicate Finder's folders.
+// Files that disappear are marked removed in every mode; in exact-duplicate
+// mode new and changed files are hashed and join the groups they match.
+func (s *FileScanner) watchDuplicates(on bool) {
+	s.mu.Lock()
+	old := s.dfWatcher
+	s.dfWatcher = nil
+	roots, filter := s.dfRoots, s.dfFilter
+	found := len(s.allFileItems) > 0 || s.dfIndex != nil // new files may still form groups
+	s.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+	if !on || !found {
+		return
+	}
+	rw, err := startWatcher(outermostRoots(roots), filter, s.walkOptions())
+	if err != nil {
+		fmt.Println("Error starting watch mode:", err)
+		return
+	}
+	rw.apply = func(changed []string) { s.updateDuplicates(rw, changed) }
+	s.mu.Lock()
+	s.dfWatcher = rw
+	s.mu.Unlock()
+	go rw.run()
+}
+
+// watchedFile is a new or changed file, hashed for the Duplicate Finder.
+type watchedFile struct {
+	path    string
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+// updateDuplicates hashes the changed files on the watcher goroutine, then
+// hands the result to applyDuplicateChanges.
+func (s *FileScanner) updateDuplicates(rw *resultWatcher, changed []string) {
+	s.mu.Lock()
+	idx := s.dfIndex
+	s.mu.Unlock()
+
+	var gone []string
+	var found []watchedFile
+	now := time.Now()
+	for _, p := range changed {
+		st, err := os.Stat(p)
+		if err != nil {
+			gone = append(gone, p)
+			continue
+		}
+		if idx == nil || st.IsDir() || st.Size() == 0 || !idx.filter.matches(p, st, now) {
+			continue
+		}
+		sum, err := s.generateHash(p, idx.algo)
+		if err != nil {
+			continue
+		}
+		h := watchedFile{p, fmt.Sprintf("%s-%d", sum, st.Size()), st.Size(), st.ModTime()}
+		if idx.verify && !s.sameAsCopies(idx, h.path, h.key) {
+			continue // a hash collision, not a copy
+		}
+		found = append(found, h)
+	}
+	s.saveHashCache()
+	s.postUI(func() { s.applyDuplicateChanges(rw, idx, gone, found) })
+}
+
+// applyDuplicateChanges marks files that are gone as removed, moves changed
+// files to the groups they now match and drops groups with fewer than two
+// files left.
+func (s *FileScanner) applyDuplicateChanges(rw *resultWatcher, idx *hashIndex, gone []string, found []watchedFile) {
+	s.mu.Lock()
+	if s.dfWatcher != rw {
+		s.mu.Unlock()
+		return // stopped or replaced meanwhile
+	}
+	byPath := make(map[string]*FileItem)
+	for _, fi := range s.allFileItems {
+		byPath[fi.filePath] = fi
+	}
+	for _, p := range gone {
+		for _, fi := range s.allFileItems {
+			if !fi.removed && (isUnder(fi.filePath, p) || isUnder(fi.archive, p)) {
+				fi.removed, fi.selected = true, false
+				fi.note = joinNote(fi.note, "removed")
+			}
+		}
+		if idx != nil {
+			for q := range idx.keys {
+				if isUnder(q, p) {
+					idx.remove(q)
+				}
+			}
+		}
+	}
+	for _, h := range found {
+		if fi := byPath[h.path]; fi != nil && !fi.removed && idx.keys[h.path] == h.key {
+			continue // unchanged
+		}
+		// a changed file leaves its old group
+		idx.remove(h.path)
+		for key, group := range s.allDuplicates {
+			s.allDuplicates[key] = without(group, h.path)
+		}
+		delete(byPath, h.path)
+
+		idx.add(h.path, h.key)
+		copies := idx.paths[h.key]
+		if len(copies) < 2 {
+			continue
+		}
+		key := h.key
+		for k, group := range s.allDuplicates {
+			if contains(group, copies[0]) {
+				key = k
+				break
+			}
+		}
+		for _, p := range copies {
+			if byPath[p] != nil || contains(s.allDuplicates[key], p) {
+				continue
+			}
+			fi := &FileItem{filePath: p, size: h.size, modTime: h.modTime}
+			if p != h.path {
+				if st, err := os.Stat(p); err == nil {
+					fi.modTime = st.ModTime()
+				}
+			}
+			if r, _ := rootOf(s.dfRoots, p); r.Reference {
+				fi.reference, fi.note = true, "reference"
+			}
+			byPath[p] = fi
+			s.allDuplicates[key] = append(s.allDuplicates[key], p)
+		}
+		hasReference := false
+		for _, p := range s.allDuplicates[key] {
+			if fi := byPath[p]; fi != nil && fi.reference {
+				hasReference = true
+			}
+		}
+		if fi := byPath[h.path]; hasReference && !fi.reference {
+			fi.selected = true
+		}
+	}
+
+	// drop groups with fewer than two files left, and files in no group
+	inGroup := make(map[string]bool)
+	for key, group := range s.allDuplicates {
+		live := 0
+		for _, p := range group {
+			if fi := byPath[p]; fi != nil && !fi.removed {
+				live++
+			}
+		}
+		if live < 2 {
+			delete(s.allDuplicates, key)
+			continue
+		}
+		for _, p := range group {
+			inGroup[p] = true
+		}
+	}
+	var items []*FileItem
+	for _, fi := range s.allFileItems {
+		if inGroup[fi.filePath] && byPath[fi.filePath] == fi {
+			items = append(items, fi)
+			delete(byPath, fi.filePath)
+		}
+	}
+	for _, fi := range byPath {
+		if inGroup[fi.filePath] {
+			items = append(items, fi)
+		}
+	}
+	s.allFileItems = items
+	s.mu.Unlock()
+	s.refreshDuplicates()
+}
+
+// sameAsCopies compares a new file byte for byte with one of the files
+// sharing its key, so a fast-hash collision does not join a group.
+func (s *FileScanner) sameAsCopies(idx *hashIndex, p, key string) bool {
+	s.mu.Lock()
+	var other string
+	for _, q := range idx.paths[key] {
+		if q != p {
+			other = q
+			break
+		}
+	}
+	// idx changes on the UI goroutine, so the archive is looked up here
+	archives := map[string]string{other: idx.archives[other]}
+	s.mu.Unlock()
+	if other == "" {
+		return true
+	}
+	same, err := sameFileContent(p, other, archives)
+	return err == nil && same
+}
+
+func joinNote(note, s string) string {
+	if note == "" {
+		return s
+	}
+	return note + ", " + s
+}
+
+func without(list []string, s string) []string {
+	var res []string
+	for _, v := range list {
+		if v != s {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// ---------------------------------------------------------------------
+//  3) Space Cleaner
+// ---------------------------------------------------------------------
+
+// watchLargeFiles starts or stops watching the Space Cleaner's targets.
+// Files that disappear are marked removed; new and changed files are
+// listed or dropped by the filter of their target.
+func (s *FileScanner) watchLargeFiles(on bool) {
+	s.mu.Lock()
+	old := s.scWatcher
+	s.scWatcher = nil
+	targets := s.scTargets
+	s.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+	if !on || len(targets) == 0 {
+		return
+	}
+	var dirs []string
+	var filter FileFilter
+	for _, t := range targets {
+		dirs = append(dirs, t.dir)
+		filter.ExcludeDirs = append(filter.ExcludeDirs, t.filter.ExcludeDirs...)
+		filter.SkipHidden = filter.SkipHidden || t.filter.SkipHidden
+		filter.SkipSystem = filter.SkipSystem || t.filter.SkipSystem
+	}
+	rw, err := startWatcher(dirs, filter, s.walkOptions())
+	if err != nil {
+		fmt.Println("Error starting watch mode:", err)
+		return
+	}
+	rw.apply = func(changed []string) { s.updateLargeFiles(rw, changed) }
+	s.mu.Lock()
+	s.scWatcher = rw
+	s.mu.Unlock()
+	go rw.run()
+}
+
+// updateLargeFiles brings the Space Cleaner list up to date with changed
+// paths. The treemap keeps the sizes of the last scan.
+func (s *FileScanner) updateLargeFiles(rw *resultWatcher, changed []string) {
+	now := time.Now()
+	stats := make([]os.FileInfo, len(changed)) // nil = gone
+	for i, p := range changed {
+		stats[i], _ = os.Stat(p)
+	}
+	s.postUI(func() { s.applyLargeFileChanges(rw, changed, stats, now) })
+}
+
+func (s *FileScanner) applyLargeFileChanges(rw *resultWatcher, changed []string, stats []os.FileInfo, now time.Time) {
+	s.mu.Lock()
+	if s.scWatcher != rw {
+		s.mu.Unlock()
+		return
+	}
+	byPath := make(map[string]*LargeFileItem)
+	for _, lf := range s.largeFileItems {
+		byPath[lf.filePath] = lf
+	}
+	for i, p := range changed {
+		st := stats[i]
+		if st == nil {
+			for _, lf := range s.largeFileItems {
+				if isUnder(lf.filePath, p) {
+					lf.removed, lf.selected = true, false
+				}
+			}
+			continue
+		}
+		if st.IsDir() {
+			continue
+		}
+		var target *scanTarget
+		for i := range s.scTargets {
+			if isUnder(p, s.scTargets[i].dir) {
+				target = &s.scTargets[i]
+				break
+			}
+		}
+		lf := byPath[p]
+		switch {
+		case target == nil || !target.filter.matches(p, st, now):
+			if lf != nil {
+				s.largeFileItems = dropLargeFile(s.largeFileItems, lf)
+			}
+		case lf != nil:
+			lf.size, lf.removed = st.Size(), false
+		default:
+			lf = &LargeFileItem{filePath: p, size: st.Size(), category: fileCategory(p)}
+			byPath[p] = lf
+			s.largeFileItems = append(s.largeFileItems, lf)
+		}
+	}
+	sort.SliceStable(s.largeFileItems, func(i, j int) bool {
+		return s.largeFileItems[i].size > s.largeFileItems[j].size
+	})
+	s.mu.Unlock()
+	s.updateCategorySelect()
+	s.refreshLargeFiles()
+}
+
+func dropLargeFile(items []*LargeFileItem, lf *LargeFileItem) []*LargeFileItem {
+	var res []*LargeFileItem
+	for _, it := range items {
+		if it != lf {
+			res = append(res, it)
+		}
+	}
+	return res
+}